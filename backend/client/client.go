@@ -0,0 +1,168 @@
+// backend/client/client.go
+
+// Package client calls the AI Documentation Assistant API. The methods and
+// types in client_gen.go are generated from the server's route table; run
+// `go generate ./client` after changing a route or a models type.
+package client
+
+//go:generate go run ../cmd/apigen -out client_gen.go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client is safe for concurrent use once configured.
+type Client struct {
+	// BaseURL is the server root, e.g. http://localhost:8080.
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token is sent as a bearer token; the admin operations need one.
+	Token string
+	// APIKey and Collection attribute usage, as the X-API-Key and
+	// X-Collection headers.
+	APIKey     string
+	Collection string
+}
+
+// New returns a client for the server at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// Error is a problem document returned by the server.
+type Error struct {
+	Problem
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s (%d %s): %s", e.Code, e.Status, e.Title, e.Detail)
+	}
+	return fmt.Sprintf("%s (%d %s)", e.Code, e.Status, e.Title)
+}
+
+// do sends body as JSON and decodes the response into out unless out is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := c.send(ctx, method, path, query, body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// stream returns the raw server-sent event stream; the caller closes it.
+func (c *Client) stream(ctx context.Context, method, path string, query url.Values, body any) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, query, body, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any, accept string) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s %s request: %w", method, path, err)
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.Collection != "" {
+		req.Header.Set("X-Collection", c.Collection)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		e := &Error{}
+		if err := json.NewDecoder(resp.Body).Decode(&e.Problem); err != nil || e.Code == "" {
+			e.Problem = Problem{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode), Code: "unknown"}
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// Event is one server-sent event from a chat stream.
+type Event struct {
+	ID   string
+	Name string
+	Data json.RawMessage
+}
+
+// ReadEvents calls fn for each event in a stream returned by a ChatStream
+// method until the stream ends, fn returns an error, or reading fails.
+// Keep-alive comments are skipped. The v2 event names and payloads are
+// start (StreamStart), sources (StreamSources), delta (StreamDelta), usage
+// (TokenUsage), done (StreamDone) and error (Problem).
+func ReadEvents(r io.Reader, fn func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var e Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				e.Data = json.RawMessage(strings.Join(data, "\n"))
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			e, data = Event{}, nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			e.ID = value
+		case "event":
+			e.Name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,39 @@
+// backend/cmd/apigen/main.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/openapi"
+)
+
+// apigen writes the Go client (and optionally the OpenAPI document) for the
+// routes in internal/api.
+func main() {
+	out := flag.String("out", "client_gen.go", "where to write the generated client")
+	spec := flag.String("spec", "", "also write the OpenAPI document here")
+	flag.Parse()
+
+	src, err := openapi.GenerateClient("client", "cmd/apigen", apierror.Problem{}, api.Operations())
+	if err != nil {
+		log.Fatalf("failed to generate client: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("failed to write client: %v", err)
+	}
+
+	if *spec != "" {
+		doc, err := json.MarshalIndent(api.Spec(), "", "  ")
+		if err != nil {
+			log.Fatalf("failed to render spec: %v", err)
+		}
+		if err := os.WriteFile(*spec, append(doc, '\n'), 0o644); err != nil {
+			log.Fatalf("failed to write spec: %v", err)
+		}
+	}
+}
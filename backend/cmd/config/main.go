@@ -0,0 +1,59 @@
+// backend/cmd/config/main.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+)
+
+const usage = `usage: config [-config path] <command>
+
+commands:
+  print     show the effective configuration with secrets redacted
+  validate  check the configuration and exit non-zero on problems
+`
+
+func main() {
+	_ = godotenv.Load()
+
+	configPath := flag.String("config", "", "path to a YAML config file (defaults to $CONFIG_FILE)")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	switch flag.Arg(0) {
+	case "print":
+		out, err := cfg.Redacted().YAML()
+		if err != nil {
+			log.Fatalf("failed to render config: %v", err)
+		}
+		os.Stdout.Write(out)
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nconfiguration is invalid:\n%v\n", err)
+			os.Exit(1)
+		}
+	case "validate":
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "configuration is invalid:\n%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("configuration is valid")
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -1,19 +1,38 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"strconv"
+	"text/tabwriter"
 
-	_ "github.com/lib/pq"
 	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/yourname/ai-documentation-assistant/internal/migrate"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/migrations"
 )
 
+const usage = `usage: migrate <command> [args]
+
+commands:
+  up             apply all pending migrations (default)
+  down [n]       roll back the last n migrations (default 1)
+  status         list migrations and whether they are applied
+  goto <version> migrate up or down to exactly <version> (0 rolls back everything)
+  check          compare the live schema with the Go models and report drift
+`
+
 func main() {
 	_ = godotenv.Load()
 
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
 		log.Fatal("DATABASE_URL is required")
@@ -25,31 +44,94 @@ func main() {
 	}
 	defer db.Close()
 
-	sqlBytes, err := os.ReadFile("migrations/001_initial_schema.sql")
+	all, err := migrate.Load(migrations.FS)
 	if err != nil {
-		log.Fatalf("failed to read migration file: %v", err)
+		log.Fatalf("failed to load migrations: %v", err)
 	}
+	runner := migrate.NewRunner(db, all)
+	runner.Logf = log.Printf
 
-	statements := splitSQL(string(sqlBytes))
-	for _, stmt := range statements {
-		if strings.TrimSpace(stmt) == "" {
-			continue
+	ctx := context.Background()
+	cmd := flag.Arg(0)
+	if cmd == "" {
+		cmd = "up"
+	}
+
+	switch cmd {
+	case "up":
+		err = runner.Up(ctx)
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("down: step count must be a positive integer")
+			}
+		}
+		err = runner.Down(ctx, steps)
+	case "goto":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
 		}
-		if _, err := db.Exec(stmt); err != nil {
-			log.Fatalf("migration failed: %v\n---\n%s\n---", err, stmt)
+		version, perr := strconv.ParseInt(flag.Arg(1), 10, 64)
+		if perr != nil {
+			log.Fatalf("goto: version must be an integer")
 		}
+		err = runner.Goto(ctx, version)
+	case "status":
+		err = printStatus(ctx, runner)
+	case "check":
+		err = checkDrift(ctx, db)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %v", cmd, err)
 	}
 
-	fmt.Println("migrations applied")
+	if cmd != "status" && cmd != "check" {
+		version, err := migrate.CurrentVersion(ctx, db)
+		if err != nil {
+			log.Fatalf("failed to read schema version: %v", err)
+		}
+		fmt.Printf("schema at version %d\n", version)
+	}
 }
 
-// naive SQL splitter; good enough for our single migration file
-func splitSQL(input string) []string {
-	parts := strings.Split(input, ";")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		out = append(out, p+";")
+func printStatus(ctx context.Context, runner *migrate.Runner) error {
+	statuses, unknown, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, at := "pending", ""
+		if s.Applied {
+			state, at = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+	}
+	for _, a := range unknown {
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", a.Version, a.Name, "applied (unknown to this build)", a.AppliedAt.Format("2006-01-02 15:04:05"))
 	}
-	return out
+	return w.Flush()
 }
 
+func checkDrift(ctx context.Context, db *sql.DB) error {
+	drifts, err := migrate.Check(ctx, db, models.Tables()...)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		fmt.Println("schema matches models")
+		return nil
+	}
+	for _, d := range drifts {
+		fmt.Println(d)
+	}
+	return fmt.Errorf("%d schema drift(s) found", len(drifts))
+}
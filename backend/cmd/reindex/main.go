@@ -0,0 +1,102 @@
+// backend/cmd/reindex/main.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// reindex re-embeds every document into a new embedding space alongside the
+// existing vectors, then switches search over in one transaction. Interrupt
+// it at any time; running it again resumes from where it stopped.
+func main() {
+	_ = godotenv.Load()
+
+	configPath := flag.String("config", "", "path to a YAML config file (defaults to $CONFIG_FILE)")
+	model := flag.String("model", "", "embedding model to re-index into (defaults to the configured model)")
+	dimensions := flag.Int("dimensions", 0, "vector size (defaults to the model's native size)")
+	batchSize := flag.Int("batch", 100, "documents per embedding batch")
+	prune := flag.Bool("prune", false, "delete vectors from other embedding spaces after switching")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+
+	fallback, _ := cfg.Providers.OpenAI.EmbeddingSpace()
+	if *model == "" {
+		*model = fallback.Model
+		if *dimensions == 0 {
+			*dimensions = fallback.Dimensions
+		}
+	}
+	target, err := models.ResolveEmbeddingSpace(*model, *dimensions)
+	if err != nil {
+		log.Fatalf("invalid target: %v", err)
+	}
+
+	db, err := database.New(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if sqlDB, err := db.DB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pg := store.NewPostgres(db.DB)
+	reindexer := services.NewReindexer(pg, services.NewOpenAIEmbedder(cfg.Providers.OpenAI, nil), target, fallback)
+	reindexer.Index = &cfg.Retrieval.Index
+	reindexer.Chunking = cfg.Chunking
+	reindexer.Progress = func(job models.ReindexJob) {
+		pct := 100.0
+		if job.TotalDocuments > 0 {
+			pct = float64(job.ProcessedDocuments) / float64(job.TotalDocuments) * 100
+		}
+		log.Printf("job %d: %d/%d documents (%.1f%%), %d tokens, status %s",
+			job.ID, job.ProcessedDocuments, job.TotalDocuments, pct, job.EmbeddingTokens, job.Status)
+	}
+
+	job, err := reindexer.Start(ctx, *batchSize)
+	switch {
+	case errors.Is(err, services.ErrAlreadyActive):
+		log.Printf("%s is already the active embedding space", target)
+	case err != nil:
+		log.Fatalf("failed to start: %v", err)
+	default:
+		log.Printf("job %d: re-indexing into %s from document %d", job.ID, target, job.LastDocumentID)
+		if err := reindexer.Run(ctx, job); err != nil {
+			if ctx.Err() != nil {
+				log.Fatalf("interrupted; job %d paused, run again to resume", job.ID)
+			}
+			log.Fatalf("failed: %v", err)
+		}
+		log.Printf("job %d: done, search now uses %s", job.ID, target)
+	}
+
+	if *prune {
+		removed, err := pg.PruneEmbeddings(ctx, target)
+		if err != nil {
+			log.Fatalf("prune failed: %v", err)
+		}
+		log.Printf("pruned %d vectors from other embedding spaces", removed)
+	}
+}
@@ -2,13 +2,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/yourname/ai-documentation-assistant/internal/api"
 	"github.com/yourname/ai-documentation-assistant/internal/config"
 	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+	"github.com/yourname/ai-documentation-assistant/internal/tracing"
 )
 
 func main() {
@@ -17,45 +27,81 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
-	cfg := config.Load()
+	configPath := flag.String("config", "", "path to a YAML config file (defaults to $CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	logging.Setup(cfg.Logging, os.Stdout)
+	logger := logging.For("app")
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("flushing traces failed", "error", err)
+		}
+	}()
 
 	// Initialize database
 	db, err := database.New(cfg.Database.URL)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("failed to connect to database", err)
 	}
 	// Close underlying SQL connection pool on shutdown
 	if sqlDB, err := db.DB.DB(); err == nil {
 		defer sqlDB.Close()
 	}
 
-	// Wire deps into the api package
-	api.Init(cfg, db.DB)
-
-	// Setup Gin router
-	router := setupRouter(cfg)
-
-	// Setup API routes
-	api.SetupRoutes(router)
-
-	// Start server
-	log.Printf("Starting server on port %s", cfg.Port)
-	if err := router.Run(":" + cfg.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Changing the embedding model or size leaves older vectors unsearchable
+	// until the corpus is re-embedded; say so loudly at startup.
+	if configured, err := cfg.Providers.OpenAI.EmbeddingSpace(); err == nil {
+		active, err := store.NewPostgres(db.DB).ActiveEmbeddingSpace(context.Background(), configured)
+		if err != nil {
+			logger.Warn("could not read active embedding space", "error", err)
+		} else if active != configured {
+			logger.Warn("search uses a different embedding space than configured; run cmd/reindex to switch",
+				"active", active.String(), "configured", configured.String())
+		} else if missing, err := db.CountMissingEmbeddings(active); err != nil {
+			logger.Warn("could not check embeddings", "space", active.String(), "error", err)
+		} else if missing > 0 {
+			logger.Warn("documents without an embedding will not appear in search results until re-embedded",
+				"space", active.String(), "documents", missing)
+		}
 	}
-}
 
-func setupRouter(cfg *config.Config) *gin.Engine {
-	if cfg.Environment == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	deps, err := api.DefaultDeps(cfg, db.DB)
+	if err != nil {
+		fatal("failed to set up API", err)
+	}
+	server, err := api.NewServer(deps)
+	if err != nil {
+		fatal("failed to set up API", err)
 	}
 
-	router := gin.New()
+	// Serve until SIGINT/SIGTERM, then drain.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(api.CorsMiddleware(cfg.Security.CORS))
+	logger.Info("starting server", "port", cfg.Server.Port)
+	if err := server.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal("server failed", err)
+	}
+	logger.Info("server stopped")
+}
 
-	return router
+// fatal logs err and exits. Deferred cleanup does not run.
+func fatal(msg string, err error) {
+	logging.For("app").Error(msg, "error", err)
+	os.Exit(1)
 }
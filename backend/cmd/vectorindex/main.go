@@ -0,0 +1,141 @@
+// backend/cmd/vectorindex/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+const usage = `usage: vectorindex [flags] <command> [args]
+
+commands:
+  list           show HNSW/IVFFlat indexes on embeddings and whether they are valid
+  create         build the index for an embedding space if it does not exist
+  rebuild        build a replacement with the current parameters and swap it in
+  drop <name>    drop an index by name
+
+Indexes are built with CREATE INDEX CONCURRENTLY, so writes continue while
+they build. Build parameters default to retrieval.index in the config.
+
+flags:
+`
+
+func main() {
+	_ = godotenv.Load()
+
+	configPath := flag.String("config", "", "path to a YAML config file (defaults to $CONFIG_FILE)")
+	model := flag.String("model", "", "embedding model (defaults to the active embedding space)")
+	dimensions := flag.Int("dimensions", 0, "vector size (defaults to the model's native size)")
+	method := flag.String("method", "", "hnsw or ivfflat (defaults to retrieval.index.method)")
+	m := flag.Int("m", 0, "hnsw: max connections per layer")
+	efConstruction := flag.Int("ef-construction", 0, "hnsw: candidate list size while building")
+	lists := flag.Int("lists", -1, "ivfflat: number of lists (0 = rows / 1000)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+
+	db, err := database.New(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if sqlDB, err := db.DB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd := flag.Arg(0)
+	switch cmd {
+	case "", "list":
+		err = list(ctx, db)
+	case "create", "rebuild":
+		ix := cfg.Retrieval.Index
+		if *method != "" {
+			ix.Method = *method
+		}
+		if *m > 0 {
+			ix.M = *m
+		}
+		if *efConstruction > 0 {
+			ix.EfConstruction = *efConstruction
+		}
+		if *lists >= 0 {
+			ix.Lists = *lists
+		}
+
+		var space models.EmbeddingSpace
+		if *model != "" {
+			space, err = models.ResolveEmbeddingSpace(*model, *dimensions)
+		} else {
+			fallback, _ := cfg.Providers.OpenAI.EmbeddingSpace()
+			space, err = store.NewPostgres(db.DB).ActiveEmbeddingSpace(ctx, fallback)
+		}
+		if err != nil {
+			log.Fatalf("invalid embedding space: %v", err)
+		}
+
+		spec := services.VectorIndexSpec(ix, space)
+		log.Printf("%s %s for %s; this can take a while on a large corpus", cmd, spec.Name(), space)
+		if cmd == "create" {
+			err = database.CreateVectorIndex(ctx, db.DB, spec)
+		} else {
+			err = database.RebuildVectorIndex(ctx, db.DB, spec)
+		}
+		if err == nil {
+			log.Printf("%s ready", spec.Name())
+		}
+	case "drop":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		err = database.DropVectorIndex(ctx, db.DB, flag.Arg(1))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %v", cmd, err)
+	}
+}
+
+func list(ctx context.Context, db *database.Database) error {
+	indexes, err := database.ListVectorIndexes(ctx, db.DB)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMETHOD\tSIZE\tVALID")
+	for _, ix := range indexes {
+		valid := "yes"
+		if !ix.Valid {
+			valid = "NO (interrupted build; rebuild or drop)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.1f MB\t%s\n", ix.Name, ix.Method, float64(ix.SizeBytes)/(1<<20), valid)
+	}
+	return w.Flush()
+}
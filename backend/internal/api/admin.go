@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// startReindexHandler starts (or resumes) re-embedding the corpus into a new
+// embedding space in the background and returns the job to poll.
+func (h *Handlers) startReindexHandler(c *gin.Context) {
+	var req models.ReindexRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		invalid(c, "%v", err)
+		return
+	}
+
+	oa := h.cfg.Providers.OpenAI
+	fallback, err := oa.EmbeddingSpace()
+	if err != nil {
+		abort(c, apierror.Wrap(apierror.CodeMisconfigured, err, "The configured embedding model is invalid."))
+		return
+	}
+	// With no model given, re-index into whatever the config now says.
+	if req.Model == "" {
+		req.Model = fallback.Model
+		if req.Dimensions == 0 {
+			req.Dimensions = fallback.Dimensions
+		}
+	}
+	target, err := models.ResolveEmbeddingSpace(req.Model, req.Dimensions)
+	if err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+
+	reindexer := services.NewReindexer(h.reindex, h.embedder, target, fallback)
+	reindexer.Index = &h.cfg.Retrieval.Index
+	reindexer.Chunking = h.cfg.Chunking
+	job, err := reindexer.Start(c.Request.Context(), req.BatchSize)
+	switch {
+	case errors.Is(err, services.ErrAlreadyActive), errors.Is(err, services.ErrReindexRunning):
+		abort(c, apierror.Wrap(apierror.CodeConflict, err, err.Error()))
+		return
+	case err != nil:
+		abort(c, apierror.Database(err, "Failed to start reindex."))
+		return
+	}
+
+	// The job outlives this request; progress is persisted on the job row.
+	// It is paused, not failed, if the server shuts down first. The response
+	// is the job as claimed, since the run updates it from here on.
+	accepted := *job
+	h.goBackground(func(ctx context.Context) {
+		if err := reindexer.Run(ctx, job); err != nil {
+			logging.For("reindex").ErrorContext(ctx, "reindex job stopped", "job_id", job.ID, "error", err)
+			return
+		}
+		logging.For("reindex").InfoContext(ctx, "reindex job completed", "job_id", job.ID, "space", target.String())
+	})
+
+	c.JSON(http.StatusAccepted, accepted)
+}
+
+func (h *Handlers) listReindexJobsHandler(c *gin.Context) {
+	jobs, err := h.reindex.ListReindexJobs(c.Request.Context(), 20)
+	if err != nil {
+		abort(c, apierror.Database(err, "Failed to load reindex jobs."))
+		return
+	}
+	c.JSON(http.StatusOK, models.ReindexJobListResponse{Jobs: jobs})
+}
+
+func (h *Handlers) getReindexJobHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalid(c, "job id must be a positive integer")
+		return
+	}
+	job, err := h.reindex.GetReindexJob(c.Request.Context(), uint(id))
+	if errors.Is(err, store.ErrNotFound) {
+		abort(c, apierror.Newf(apierror.CodeNotFound, "Reindex job %d does not exist.", id))
+		return
+	}
+	if err != nil {
+		abort(c, apierror.Database(err, "Failed to load reindex job."))
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
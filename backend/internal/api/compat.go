@@ -0,0 +1,382 @@
+// backend/internal/api/compat.go
+package api
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+// The /v1 routes speak the OpenAI API, so OpenAI SDKs and tools can use this
+// server by changing their base URL. Chat completions are grounded like
+// /chat: the documents closest to the last user message are put in front of
+// the conversation as a system message. Only the configured models can be
+// used; everything else in the request is passed to the provider as sent,
+// and responses, streams and errors come back in OpenAI's shapes rather than
+// this API's.
+
+// compatAuth requires the bearer token OpenAI clients always send to be a
+// key from auth.api_keys or a JWT: these routes spend the server's provider
+// key.
+func compatAuth(auth config.AuthConfig) gin.HandlerFunc {
+	keys := newAPIKeys(auth.APIKeys)
+	return func(c *gin.Context) {
+		token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		var e *apierror.Error
+		switch _, isKey := keys.lookup(token); {
+		case token == "":
+			e = apierror.New(apierror.CodeUnauthorized, "An API key is required, as Authorization: Bearer <key>.")
+		case isKey || auth.JWTSecret == "":
+			e = keys.verify(c, token)
+		default:
+			e = verifyToken(c, auth.JWTSecret, token)
+		}
+		if e != nil {
+			compatAbort(c, e)
+			return
+		}
+		c.Next()
+	}
+}
+
+// modelNotFound refuses a model other than the configured ones, so callers
+// cannot choose what the server's provider key pays for.
+func modelNotFound(model string) *apierror.Error {
+	return apierror.Newf(apierror.CodeModelNotFound, "The model %q does not exist or you do not have access to it.", model)
+}
+
+type compatError struct {
+	Error compatErrorDetail `json:"error"`
+}
+
+type compatErrorDetail struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    string  `json:"code"`
+}
+
+// compatProblem converts e to an OpenAI error.
+func compatProblem(c *gin.Context, e *apierror.Error) compatError {
+	p := problem(c, e)
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	kind := "api_error"
+	switch {
+	case e.Status == http.StatusUnauthorized:
+		kind = "authentication_error"
+	case e.Status == http.StatusTooManyRequests:
+		kind = "rate_limit_error"
+	case e.Status < http.StatusInternalServerError:
+		kind = "invalid_request_error"
+	}
+	return compatError{Error: compatErrorDetail{Message: message, Type: kind, Code: string(e.Code)}}
+}
+
+// compatAbort is abort for the /v1 routes.
+func compatAbort(c *gin.Context, err error) {
+	e := logError(c, err)
+	if e.RetryAfterSeconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(e.RetryAfterSeconds))
+	}
+	c.AbortWithStatusJSON(e.Status, compatProblem(c, e))
+}
+
+func (h *Handlers) listModelsHandler(c *gin.Context) {
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	}
+	oa := h.cfg.Providers.OpenAI
+	data := []model{{ID: oa.ChatModel, Object: "model", OwnedBy: "system"}}
+	if oa.EmbeddingModel != oa.ChatModel {
+		data = append(data, model{ID: oa.EmbeddingModel, Object: "model", OwnedBy: "system"})
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data})
+}
+
+// compatMessages converts an OpenAI conversation for retrieveContext,
+// keeping only the text of each message.
+func compatMessages(messages []openai.ChatCompletionMessage) []models.Message {
+	out := make([]models.Message, len(messages))
+	for i, m := range messages {
+		out[i] = models.Message{Role: m.Role, Content: messageText(m)}
+	}
+	return out
+}
+
+// messageText is m's content, or the text parts of a multi-part message.
+func messageText(m openai.ChatCompletionMessage) string {
+	if m.Content != "" {
+		return m.Content
+	}
+	var parts []string
+	for _, part := range m.MultiContent {
+		if part.Type == openai.ChatMessagePartTypeText {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (h *Handlers) chatCompletionsHandler(c *gin.Context) {
+	var req openai.ChatCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		compatAbort(c, apierror.Newf(apierror.CodeInvalidRequest, "%v", err))
+		return
+	}
+	if len(req.Messages) == 0 {
+		compatAbort(c, apierror.New(apierror.CodeInvalidRequest, "messages must not be empty."))
+		return
+	}
+	switch chatModel := h.cfg.Providers.OpenAI.ChatModel; req.Model {
+	case "":
+		req.Model = chatModel
+	case chatModel:
+	default:
+		compatAbort(c, modelNotFound(req.Model))
+		return
+	}
+	sc := scopeOf(c)
+	sc.endpoint = "v1_chat_completions"
+	g, err := h.retrieveContext(c.Request.Context(), sc, compatMessages(req.Messages))
+	if err != nil {
+		compatAbort(c, err)
+		return
+	}
+	if req.Stream {
+		h.streamCompletion(c, sc, req, g)
+		return
+	}
+
+	resp, err := h.completion(c.Request.Context(), g, req)
+	if err == nil && len(resp.Choices) == 0 {
+		err = apierror.New(apierror.CodeProviderError, "The model provider returned no answer.")
+	}
+	if err != nil {
+		h.recordAnswerUsage(sc, g, models.UsageEvent{})
+		compatAbort(c, err)
+		return
+	}
+	h.logAnswer(sc, g, resp.Choices[0].Message.Content)
+	h.recordAnswerUsage(sc, g, models.UsageEvent{
+		ChatModel:        chatModelName(resp.Model, req.Model),
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	})
+	c.JSON(http.StatusOK, resp)
+}
+
+// streamCompletion relays the provider's chunks as OpenAI does: one data
+// line per chunk, then data: [DONE]. A failure part way is sent as a data
+// line holding an OpenAI error.
+func (h *Handlers) streamCompletion(c *gin.Context, sc requestScope, req openai.ChatCompletionRequest, g grounding) {
+	// Usage is always asked for, for accounting, but only passed on to
+	// clients that asked for it too.
+	wantUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	usage := models.UsageEvent{ChatModel: req.Model}
+
+	stream, err := h.completionStream(c.Request.Context(), g, req)
+	if err != nil {
+		h.recordAnswerUsage(sc, g, models.UsageEvent{})
+		compatAbort(c, err)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	var answer strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			h.recordAnswerUsage(sc, g, usage)
+			_ = send(compatProblem(c, logError(c, err)))
+			return
+		}
+		if chunk.Usage != nil {
+			usage.ChatModel = chatModelName(chunk.Model, req.Model)
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+			if !wantUsage {
+				if len(chunk.Choices) == 0 {
+					continue
+				}
+				chunk.Usage = nil
+			}
+		}
+		if len(chunk.Choices) > 0 {
+			answer.WriteString(chunk.Choices[0].Delta.Content)
+		}
+		if err := send(chunk); err != nil {
+			break // the client went away; its context cancels the provider call
+		}
+	}
+
+	h.logAnswer(sc, g, answer.String())
+	h.recordAnswerUsage(sc, g, usage)
+	_, _ = io.WriteString(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+type compatEmbedding struct {
+	Object    string `json:"object"`
+	Embedding any    `json:"embedding"` // []float32, or base64 little-endian float32s
+	Index     int    `json:"index"`
+}
+
+type compatEmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+type compatEmbeddingList struct {
+	Object string               `json:"object"`
+	Data   []compatEmbedding    `json:"data"`
+	Model  string               `json:"model"`
+	Usage  compatEmbeddingUsage `json:"usage"`
+}
+
+func (h *Handlers) embeddingsHandler(c *gin.Context) {
+	var req openai.EmbeddingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		compatAbort(c, apierror.Newf(apierror.CodeInvalidRequest, "%v", err))
+		return
+	}
+	texts, err := embeddingInputs(req.Input)
+	if err != nil {
+		compatAbort(c, err)
+		return
+	}
+	base64Format := false
+	switch req.EncodingFormat {
+	case "", openai.EmbeddingEncodingFormatFloat:
+	case openai.EmbeddingEncodingFormatBase64:
+		base64Format = true
+	default:
+		compatAbort(c, apierror.Newf(apierror.CodeInvalidRequest, "encoding_format must be float or base64 (got %q).", req.EncodingFormat))
+		return
+	}
+
+	space, err := h.compatEmbeddingSpace(c, string(req.Model), req.Dimensions)
+	if err != nil {
+		compatAbort(c, err)
+		return
+	}
+
+	vectors, tokens, err := h.embedder.Embed(c.Request.Context(), space, texts)
+	h.recordUsage(c, models.UsageEvent{
+		Endpoint:        "embeddings",
+		EmbeddingModel:  space.Model,
+		EmbeddingTokens: tokens,
+	})
+	if err != nil {
+		compatAbort(c, err)
+		return
+	}
+
+	list := compatEmbeddingList{
+		Object: "list",
+		Data:   make([]compatEmbedding, len(vectors)),
+		Model:  space.Model,
+		Usage:  compatEmbeddingUsage{PromptTokens: tokens, TotalTokens: tokens},
+	}
+	for i, v := range vectors {
+		list.Data[i] = compatEmbedding{Object: "embedding", Embedding: v, Index: i}
+		if base64Format {
+			buf := make([]byte, 4*len(v))
+			for j, f := range v {
+				binary.LittleEndian.PutUint32(buf[4*j:], math.Float32bits(f))
+			}
+			list.Data[i].Embedding = base64.StdEncoding.EncodeToString(buf)
+		}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// compatEmbeddingSpace is the space documents are searched in, or the
+// configured one while a re-index has yet to switch to it. No other model or
+// size is offered.
+func (h *Handlers) compatEmbeddingSpace(c *gin.Context, model string, dimensions int) (models.EmbeddingSpace, error) {
+	space, err := h.activeEmbeddingSpace(c.Request.Context())
+	if err != nil {
+		return space, err
+	}
+	if model != "" && model != space.Model {
+		configured, err := h.cfg.Providers.OpenAI.EmbeddingSpace()
+		if err != nil || configured.Model != model {
+			return space, modelNotFound(model)
+		}
+		space = configured
+	}
+	if dimensions != 0 && dimensions != space.Dimensions {
+		return space, apierror.Newf(apierror.CodeInvalidRequest, "dimensions must be %d for %s.", space.Dimensions, space.Model)
+	}
+	return space, nil
+}
+
+// embeddingInputs accepts input as a string or an array of strings. Token
+// arrays are not supported, since the embedder works on text.
+func embeddingInputs(input any) ([]string, error) {
+	var texts []string
+	switch in := input.(type) {
+	case string:
+		texts = []string{in}
+	case []any:
+		for _, item := range in {
+			s, ok := item.(string)
+			if !ok {
+				return nil, apierror.New(apierror.CodeInvalidRequest, "input must be a string or an array of strings; token arrays are not supported.")
+			}
+			texts = append(texts, s)
+		}
+	default:
+		return nil, apierror.New(apierror.CodeInvalidRequest, "input must be a string or an array of strings.")
+	}
+	if len(texts) == 0 {
+		return nil, apierror.New(apierror.CodeInvalidRequest, "input must not be empty.")
+	}
+	for _, t := range texts {
+		if t == "" {
+			return nil, apierror.New(apierror.CodeInvalidRequest, "input must not contain empty strings.")
+		}
+	}
+	return texts, nil
+}
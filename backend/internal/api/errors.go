@@ -0,0 +1,68 @@
+// backend/internal/api/errors.go
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+)
+
+// abort ends the request with err as a problem+json response. Server-side
+// failures are logged with their cause, which the client never sees.
+func abort(c *gin.Context, err error) {
+	e := logError(c, err)
+	if e.RetryAfterSeconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(e.RetryAfterSeconds))
+	}
+	c.Header("Content-Type", apierror.ContentType)
+	c.AbortWithStatusJSON(e.Status, problem(c, e))
+}
+
+// invalid aborts with a 400 invalid_request and detail.
+func invalid(c *gin.Context, format string, args ...any) {
+	abort(c, apierror.Newf(apierror.CodeInvalidRequest, format, args...))
+}
+
+func problem(c *gin.Context, e *apierror.Error) apierror.Problem {
+	return e.Problem(c.Request.URL.Path, logging.RequestID(c.Request.Context()))
+}
+
+// logError classifies err and logs it: 5xx at error, provider and
+// cancellation failures at warn, client errors not at all (the access log
+// has them).
+func logError(c *gin.Context, err error) *apierror.Error {
+	return classify(c.Request.Context(), err)
+}
+
+func classify(ctx context.Context, err error) *apierror.Error {
+	e := apierror.From(err)
+	level := slog.LevelError
+	switch {
+	case e.Code == apierror.CodeRequestCancelled:
+		level = slog.LevelDebug
+	case e.Status < http.StatusInternalServerError && e.Err == nil:
+		return e
+	case e.Status < http.StatusInternalServerError:
+		level = slog.LevelWarn
+	}
+	attrs := []any{"code", e.Code, "status", e.Status}
+	if e.Err != nil {
+		attrs = append(attrs, "error", e.Err)
+	}
+	logging.For("api").Log(ctx, level, e.Title, attrs...)
+	return e
+}
+
+// noRoute and noMethod answer unknown paths and methods with problems too.
+func noRoute(c *gin.Context) {
+	abort(c, apierror.Newf(apierror.CodeNotFound, "No route for %s.", c.Request.URL.Path))
+}
+
+func noMethod(c *gin.Context) {
+	abort(c, apierror.Newf(apierror.CodeMethodNotAllowed, "%s is not allowed on %s.", c.Request.Method, c.Request.URL.Path))
+}
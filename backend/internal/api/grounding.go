@@ -0,0 +1,183 @@
+// backend/internal/api/grounding.go
+package api
+
+import (
+	"context"
+	"io"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+)
+
+// Every way of chatting (/chat, /chat/stream, the WebSocket and /v1) answers
+// in the same steps: retrieveContext, then completion or completionStream,
+// then logAnswer and recordAnswerUsage. So they search for the same text,
+// reuse cached answers, and log and account for answers alike.
+
+// grounding is the documentation retrieved for a conversation.
+type grounding struct {
+	question  string           // the last user message, which is searched for and logged
+	context   []models.Message // a system message holding the documents; empty when none were found
+	sources   []models.SearchResult
+	retrieval services.Retrieval
+
+	// cacheable is set for a standalone question: only those reuse earlier
+	// answers, and only their answers are stored for reuse. cached is the
+	// answer being reused, if any.
+	cacheable   bool
+	fingerprint string
+	cached      *services.CachedAnswer
+}
+
+// retrieveContext finds the documents closest to the last user message and
+// looks for an earlier answer to reuse. A failed search is accounted for
+// before it is returned.
+func (h *Handlers) retrieveContext(ctx context.Context, sc requestScope, messages []models.Message) (grounding, error) {
+	var g grounding
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == openai.ChatMessageRoleUser {
+			g.question = messages[i].Content
+			break
+		}
+	}
+	if g.question == "" || h.cfg.Retrieval.ChatContextDocs <= 0 {
+		return g, nil
+	}
+
+	var err error
+	g.retrieval, err = h.search.Search(ctx, services.SearchQuery{
+		Text:  g.question,
+		Limit: h.cfg.Retrieval.ChatContextDocs,
+	})
+	if err != nil {
+		h.recordAnswerUsage(sc, g, models.UsageEvent{})
+		return g, err
+	}
+	if len(g.retrieval.Results) > 0 {
+		g.sources = g.retrieval.Results
+		g.context = h.withDocumentContext(ctx, nil, g.sources)
+	}
+
+	// A standalone question grounded in the same documents as an earlier one
+	// can reuse its answer. Follow-ups depend on history and are never cached.
+	g.cacheable = h.answers != nil && len(messages) == 1
+	if g.cacheable {
+		g.fingerprint = services.SourcesFingerprint(g.sources)
+		hit, ok, err := h.answers.Lookup(ctx, h.cfg.Providers.OpenAI.ChatModel, g.retrieval.Space, g.retrieval.Vector, g.fingerprint)
+		if err != nil {
+			logging.For("cache").WarnContext(ctx, "answer cache lookup failed", "error", err)
+		} else if ok {
+			g.cached = hit
+		}
+	}
+	return g, nil
+}
+
+// completion answers req with g's documents in front of its messages, or
+// returns the answer g reuses without calling the provider.
+func (h *Handlers) completion(ctx context.Context, g grounding, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if g.cached != nil {
+		return h.cachedCompletion(g, req.Model), nil
+	}
+	req.Messages = append(convertMessages(g.context), req.Messages...)
+	return h.chat.CreateChatCompletion(ctx, req)
+}
+
+// completionStream is completion for streams. A reused answer arrives as a
+// single chunk.
+func (h *Handlers) completionStream(ctx context.Context, g grounding, req openai.ChatCompletionRequest) (services.ChatStream, error) {
+	if g.cached != nil {
+		return newReplayStream(h.cachedCompletion(g, req.Model)), nil
+	}
+	req.Messages = append(convertMessages(g.context), req.Messages...)
+	return h.chat.CreateChatCompletionStream(ctx, req)
+}
+
+// cachedCompletion is the answer g reuses as a response from model. It used
+// no tokens.
+func (h *Handlers) cachedCompletion(g grounding, model string) openai.ChatCompletionResponse {
+	return openai.ChatCompletionResponse{
+		ID:      "chatcmpl-" + newRequestID(),
+		Object:  "chat.completion",
+		Created: h.clock.Now().Unix(),
+		Model:   model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: g.cached.Response},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}
+}
+
+// replayStream streams a finished response: its message as one chunk, then
+// its usage.
+type replayStream struct {
+	chunks []openai.ChatCompletionStreamResponse
+}
+
+func newReplayStream(resp openai.ChatCompletionResponse) *replayStream {
+	chunk := openai.ChatCompletionStreamResponse{
+		ID:      resp.ID,
+		Object:  "chat.completion.chunk",
+		Created: resp.Created,
+		Model:   resp.Model,
+	}
+	answer := chunk
+	answer.Choices = []openai.ChatCompletionStreamChoice{{
+		Delta: openai.ChatCompletionStreamChoiceDelta{
+			Role:    resp.Choices[0].Message.Role,
+			Content: resp.Choices[0].Message.Content,
+		},
+		FinishReason: resp.Choices[0].FinishReason,
+	}}
+	usage := chunk
+	usage.Usage = &resp.Usage
+	return &replayStream{chunks: []openai.ChatCompletionStreamResponse{answer, usage}}
+}
+
+func (s *replayStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	if len(s.chunks) == 0 {
+		return openai.ChatCompletionStreamResponse{}, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *replayStream) Close() error { return nil }
+
+// logAnswer logs the answer to g's question for analytics and, when it is
+// a fresh answer to a standalone question, for the answer cache.
+func (h *Handlers) logAnswer(sc requestScope, g grounding, response string) {
+	if g.question == "" {
+		return
+	}
+	q := models.UserQuery{
+		Kind:     models.QueryKindChat,
+		Query:    g.question,
+		Response: response,
+		Sources:  extractSourceURLs(g.sources),
+	}
+	if g.cacheable && g.cached == nil {
+		q.ChatModel = h.cfg.Providers.OpenAI.ChatModel
+		q.EmbeddingModel = g.retrieval.Space.Model
+		q.EmbeddingDimensions = g.retrieval.Space.Dimensions
+		q.QueryEmbedding = g.retrieval.Vector
+		q.SourcesFingerprint = g.fingerprint
+	}
+	h.logQueryFor(sc, &q)
+}
+
+// recordAnswerUsage accounts for an answer under sc's endpoint: g's
+// retrieval plus the chat model and tokens in ev, if it got that far. A
+// reused answer spent no chat tokens.
+func (h *Handlers) recordAnswerUsage(sc requestScope, g grounding, ev models.UsageEvent) {
+	ev.Endpoint = sc.endpoint
+	ev.EmbeddingModel = g.retrieval.Space.Model
+	ev.EmbeddingTokens = g.retrieval.EmbeddingTokens
+	if g.cached != nil {
+		ev.ChatModel = ""
+	}
+	h.recordUsageFor(sc, ev)
+}
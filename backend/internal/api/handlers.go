@@ -2,394 +2,365 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
 	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+	"github.com/yourname/ai-documentation-assistant/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func healthCheckHandler(c *gin.Context) {
-	st := getState()
-	env := ""
-	if st != nil && st.cfg != nil {
-		env = st.cfg.Environment
-	}
-	c.JSON(http.StatusOK, gin.H{
-		"status":      "healthy",
-		"timestamp":   time.Now().Unix(),
-		"environment": env,
+var tracer = tracing.Tracer("github.com/yourname/ai-documentation-assistant/internal/api")
+
+func (h *Handlers) healthCheckHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{
+		Status:      "healthy",
+		Timestamp:   h.clock.Now().Unix(),
+		Environment: h.cfg.Environment,
 	})
 }
 
-func searchHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.cfg == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
+// runSearch runs a search request for either API version.
+func (h *Handlers) runSearch(c *gin.Context, req models.SearchRequest) ([]models.SearchResult, error) {
+	if req.Limit == 0 {
+		req.Limit = h.cfg.Retrieval.DefaultLimit
 	}
-
-	var req models.SearchRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
-		return
+	if req.Limit > h.cfg.Retrieval.MaxLimit {
+		return nil, apierror.Newf(apierror.CodeInvalidRequest, "limit must not exceed %d", h.cfg.Retrieval.MaxLimit)
 	}
 
-	// Get embeddings for the query
-	client := openai.NewClient(st.cfg.OpenAI.APIKey)
-	embeddingResp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
-		Input: req.Query,
-		Model: openai.AdaEmbeddingV2,
+	retrieval, err := h.search.Search(c.Request.Context(), services.SearchQuery{
+		Text:   req.Query,
+		Limit:  req.Limit,
+		Tuning: services.SearchTuning{EfSearch: req.EfSearch, Probes: req.Probes},
+	})
+	h.recordUsage(c, models.UsageEvent{
+		Endpoint:        "search",
+		EmbeddingModel:  retrieval.Space.Model,
+		EmbeddingTokens: retrieval.EmbeddingTokens,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process query"})
-		return
-	}
-
-	queryEmbedding := embeddingResp.Data[0].Embedding
-
-	// Search with pgvector
-	type row struct {
-		models.Document
-		Score float64 `gorm:"column:score" json:"score"`
-	}
-	var rows []row
-	err = st.db.Raw(`
-		SELECT d.*, e.vector <=> ? AS score
-		FROM documents d
-		JOIN embeddings e ON d.id = e.document_id
-		ORDER BY e.vector <=> ?
-		LIMIT ?
-	`, queryEmbedding, queryEmbedding, req.Limit).Scan(&rows).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
-		return
-	}
-
-	results := make([]models.SearchResult, 0, len(rows))
-	for _, r := range rows {
-		results = append(results, models.SearchResult{
-			Document: r.Document,
-			Score:    r.Score,
-		})
+		return nil, err
 	}
+	results := retrieval.Results
 
 	// Log the query for analytics
-	st.db.Create(&models.UserQuery{
+	h.logQuery(c, &models.UserQuery{
+		Kind:     models.QueryKindSearch,
 		Query:    req.Query,
 		Response: fmt.Sprintf("Found %d results", len(results)),
 		Sources:  extractSourceURLs(results),
 	})
+	return results, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"results": results,
-		"query":   req.Query,
-		"count":   len(results),
-	})
+// chatReply is an answer and what went into it, for the versioned handlers
+// to render.
+type chatReply struct {
+	Message string
+	Sources []models.SearchResult
+	Cached  bool
+	Usage   models.TokenUsage
 }
 
-func chatHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.cfg == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
+// answer replies to a conversation, grounded in the documents closest to its
+// last user message.
+func (h *Handlers) answer(c *gin.Context, messages []models.Message) (chatReply, error) {
+	sc := scopeOf(c)
+	sc.endpoint = "chat"
+	g, err := h.retrieveContext(c.Request.Context(), sc, messages)
+	if err != nil {
+		return chatReply{}, err
 	}
 
-	var req models.ChatRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-		return
+	resp, err := h.completion(c.Request.Context(), g, openai.ChatCompletionRequest{
+		Model:       h.cfg.Providers.OpenAI.ChatModel,
+		Messages:    convertMessages(messages),
+		Temperature: h.cfg.Providers.OpenAI.Temperature,
+		MaxTokens:   h.cfg.Providers.OpenAI.MaxTokens,
+	})
+	if err == nil && len(resp.Choices) == 0 {
+		err = apierror.New(apierror.CodeProviderError, "The model provider returned no answer.")
 	}
-
-	// Search for relevant context first
-	var searchResults []models.SearchResult
-	if len(req.Messages) > 0 {
-		lastMessage := req.Messages[len(req.Messages)-1].Content
-		results, err := performSearch(lastMessage, 3)
-		if err == nil && len(results) > 0 {
-			searchResults = results
-			// Add context to messages
-			context := formatSearchContext(searchResults)
-			req.Messages = append([]models.Message{{
-				Role:    "system",
-				Content: fmt.Sprintf("Context from documentation: %s", context),
-			}}, req.Messages...)
-		}
+	if err != nil {
+		h.recordAnswerUsage(sc, g, models.UsageEvent{})
+		return chatReply{}, err
 	}
 
-	client := openai.NewClient(st.cfg.OpenAI.APIKey)
-	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
-		Model:       openai.GPT3Dot5Turbo,
-		Messages:    convertMessages(req.Messages),
-		Temperature: 0.7,
-		MaxTokens:   1000,
+	reply := chatReply{
+		Message: resp.Choices[0].Message.Content,
+		Sources: g.sources,
+		Cached:  g.cached != nil,
+		Usage: models.TokenUsage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			EmbeddingTokens:  g.retrieval.EmbeddingTokens,
+		},
+	}
+	h.logAnswer(sc, g, reply.Message)
+	h.recordAnswerUsage(sc, g, models.UsageEvent{
+		ChatModel:        chatModelName(resp.Model, h.cfg.Providers.OpenAI.ChatModel),
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
 	})
-	
+	return reply, nil
+}
+
+// page is one page of the document list.
+type page struct {
+	Documents []models.Document
+	Total     int64
+	Page      int
+	Limit     int
+}
+
+// listDocuments loads page n of the document list, limit documents a page.
+func (h *Handlers) listDocuments(c *gin.Context, n, limit int) (page, error) {
+	documents, total, err := h.documents.ListDocuments(c.Request.Context(), (n-1)*limit, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Chat service unavailable"})
-		return
+		return page{}, apierror.Database(err, "Failed to load documents.")
 	}
+	return page{Documents: documents, Total: total, Page: n, Limit: limit}, nil
+}
 
-	response := models.ChatResponse{
-		Message: resp.Choices[0].Message.Content,
+// pageParams reads the page and limit query parameters, rejecting values
+// outside 1..maxPageSize.
+func pageParams(c *gin.Context) (int, int, error) {
+	n, err := queryInt(c, "page", 1)
+	if err != nil || n < 1 {
+		return 0, 0, apierror.New(apierror.CodeInvalidRequest, "page must be a positive integer")
 	}
-
-	// Log for analytics (query = last user message, response = assistant message)
-	if len(req.Messages) > 0 {
-		last := req.Messages[len(req.Messages)-1].Content
-		st.db.Create(&models.UserQuery{
-			Query:    last,
-			Response: response.Message,
-			Sources:  extractSourceURLs(searchResults),
-		})
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, 0, apierror.Newf(apierror.CodeInvalidRequest, "limit must be an integer between 1 and %d", maxPageSize)
 	}
+	return n, limit, nil
+}
 
-	c.JSON(http.StatusOK, response)
+// lenientPageParams reads the page and limit query parameters the way v1
+// always has: unparseable values fall back to the defaults and the rest are
+// clamped into range.
+func lenientPageParams(c *gin.Context) (int, int) {
+	n, err := queryInt(c, "page", 1)
+	if err != nil || n < 1 {
+		n = 1
+	}
+	limit, err := queryInt(c, "limit", 10)
+	switch {
+	case err != nil:
+		limit = 10
+	case limit < 1:
+		limit = 1
+	case limit > maxPageSize:
+		limit = maxPageSize
+	}
+	return n, limit
 }
 
-func chatStreamHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.cfg == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
-	}
+// maxPageSize caps the documents returned per page.
+const maxPageSize = 100
 
-	var req models.ChatRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-		return
-	}
-
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-
-	client := openai.NewClient(st.cfg.OpenAI.APIKey)
-	
-	// Add search context
-	var searchResults []models.SearchResult
-	if len(req.Messages) > 0 {
-		lastMessage := req.Messages[len(req.Messages)-1].Content
-		results, err := performSearch(lastMessage, 3)
-		if err == nil && len(results) > 0 {
-			searchResults = results
-			context := formatSearchContext(searchResults)
-			req.Messages = append([]models.Message{{
-				Role:    "system",
-				Content: fmt.Sprintf("Context from documentation: %s", context),
-			}}, req.Messages...)
-		}
+// queryInt parses the integer query parameter key, or returns def when it
+// is absent.
+func queryInt(c *gin.Context, key string, def int) (int, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok {
+		return def, nil
 	}
+	return strconv.Atoi(raw)
+}
 
-	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
-		Model:       openai.GPT3Dot5Turbo,
-		Messages:    convertMessages(req.Messages),
-		Temperature: 0.7,
-		MaxTokens:   1000,
-		Stream:      true,
-	})
-	
-	if err != nil {
-		c.SSEvent("error", gin.H{"message": err.Error()})
-		return
+// documentID parses the :id path parameter.
+func documentID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, apierror.New(apierror.CodeInvalidRequest, "document id must be a positive integer")
 	}
-	defer stream.Close()
-
-	var streamed strings.Builder
+	return uint(id), nil
+}
 
-	for {
-		response, err := stream.Recv()
+// createDocument embeds doc and stores it, filling in its ID and timestamps.
+func (h *Handlers) createDocument(c *gin.Context, doc *models.Document) error {
+	ctx := c.Request.Context()
+	vectors := map[models.EmbeddingSpace]models.Vector{}
+	for attempt := 1; ; attempt++ {
+		spaces, err := h.documentSpaces(ctx)
 		if err != nil {
-			if err.Error() == "EOF" {
-				// Let the frontend know we're done.
-				c.Writer.WriteString("data: [DONE]\n\n")
-				c.Writer.Flush()
-
-				// Log for analytics when stream completes
-				if len(req.Messages) > 0 {
-					last := req.Messages[len(req.Messages)-1].Content
-					st.db.Create(&models.UserQuery{
-						Query:    last,
-						Response: streamed.String(),
-						Sources:  extractSourceURLs(searchResults),
-					})
+			return err
+		}
+		embeddings := make([]models.Embedding, 0, len(spaces))
+		for _, sp := range spaces {
+			vector, ok := vectors[sp]
+			if !ok {
+				var embeddingTokens int
+				vector, embeddingTokens, err = h.embedDocument(ctx, sp, doc.Content)
+				h.recordUsage(c, models.UsageEvent{
+					Endpoint:        "documents",
+					EmbeddingModel:  sp.Model,
+					EmbeddingTokens: embeddingTokens,
+				})
+				if err != nil {
+					return err
 				}
-				return
+				vectors[sp] = vector
 			}
-			c.SSEvent("error", gin.H{"message": err.Error()})
-			return
+			embeddings = append(embeddings, models.Embedding{
+				Model:      sp.Model,
+				Dimensions: sp.Dimensions,
+				Vector:     vector,
+			})
 		}
 
-		if len(response.Choices) > 0 {
-			delta := response.Choices[0].Delta
-			if delta.Content != "" {
-				// Keep it compatible with our frontend stream parser (expects data: {json}\n\n)
-				payload := fmt.Sprintf("{\"content\":%q}", delta.Content)
-				c.Writer.WriteString("data: " + payload + "\n\n")
-				c.Writer.Flush()
-				streamed.WriteString(delta.Content)
+		// A re-index started or completed while we were embedding: go
+		// round again for the spaces that are current now.
+		err = h.documents.CreateDocument(ctx, doc, embeddings)
+		if errors.Is(err, store.ErrSpaceChanged) {
+			if attempt < maxCreateAttempts {
+				continue
 			}
+			return apierror.New(apierror.CodeConflict, "The embedding space kept changing; retry once the re-index settles.")
 		}
+		if err != nil {
+			return apierror.Database(err, "Failed to create document.")
+		}
+		return nil
 	}
 }
 
-func listDocumentsHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
+// maxCreateAttempts bounds how often createDocument re-embeds for a
+// changed embedding space.
+const maxCreateAttempts = 3
+
+// documentSpaces returns the spaces a new document needs vectors in: the
+// active one and, while a re-index is running, the one it is building, so
+// the document is not missing after the flip.
+func (h *Handlers) documentSpaces(ctx context.Context) ([]models.EmbeddingSpace, error) {
+	space, err := h.activeEmbeddingSpace(ctx)
+	if err != nil {
+		return nil, apierror.Database(err, "Failed to resolve the embedding space.")
 	}
-	var documents []models.Document
-	var total int64
-	
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
-	
-	// Convert to int
-	pageInt := 1
-	limitInt := 10
-	fmt.Sscanf(page, "%d", &pageInt)
-	fmt.Sscanf(limit, "%d", &limitInt)
-	
-	offset := (pageInt - 1) * limitInt
-	
-	st.db.Model(&models.Document{}).Count(&total)
-	st.db.Offset(offset).Limit(limitInt).Find(&documents)
-	
-	c.JSON(http.StatusOK, models.DocumentListResponse{
-		Documents: documents,
-		Total:     total,
-		Page:      pageInt,
-		Limit:     limitInt,
-	})
+	spaces := []models.EmbeddingSpace{space}
+	shadow, ok, err := h.documents.RunningReindexSpace(ctx)
+	if err != nil {
+		return nil, apierror.Database(err, "Failed to check for a running re-index.")
+	}
+	if ok && shadow != space {
+		spaces = append(spaces, shadow)
+	}
+	return spaces, nil
 }
 
-func createDocumentHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.cfg == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
+func (h *Handlers) deleteDocument(c *gin.Context) error {
+	id, err := documentID(c)
+	if err != nil {
+		return err
 	}
-	var doc models.Document
-	if err := c.ShouldBindJSON(&doc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document data"})
-		return
+	err = h.documents.DeleteDocument(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		return apierror.Newf(apierror.CodeNotFound, "Document %d does not exist.", id)
 	}
-
-	// Generate embedding
-	client := openai.NewClient(st.cfg.OpenAI.APIKey)
-	embeddingResp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
-		Input: doc.Content,
-		Model: openai.AdaEmbeddingV2,
-	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate embedding"})
-		return
+		return apierror.Database(err, "Failed to delete document.")
 	}
+	return nil
+}
 
-	// Create document and embedding
-	if err := st.db.Create(&doc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create document"})
+func (h *Handlers) popularQueriesHandler(c *gin.Context) {
+	queries, err := h.queries.RecentQueries(c.Request.Context(), 10)
+	if err != nil {
+		abort(c, apierror.Database(err, "Failed to load queries."))
 		return
 	}
+	c.JSON(http.StatusOK, models.PopularQueriesResponse{Queries: queries})
+}
 
-	embedding := models.Embedding{
-		DocumentID: doc.ID,
-		Vector:     embeddingResp.Data[0].Embedding,
-	}
-	
-	if err := st.db.Create(&embedding).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create embedding"})
-		return
-	}
+func (h *Handlers) embeddingCacheStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, models.EmbeddingCacheResponse{
+		Enabled: h.embeddingCache != nil,
+		Stats:   h.embeddingCache.Stats(),
+	})
+}
 
-	c.JSON(http.StatusCreated, doc)
+// withDocumentContext prepends the retrieved documents to messages as a
+// system message.
+func (h *Handlers) withDocumentContext(ctx context.Context, messages []models.Message, results []models.SearchResult) []models.Message {
+	_, span := tracer.Start(ctx, "chat.assemble_context")
+	defer span.End()
+	context := services.FormatContextForLLM(results, h.cfg.Retrieval.ContextPreviewChars)
+	span.SetAttributes(
+		attribute.Int("context.documents", len(results)),
+		attribute.Int("context.chars", len(context)),
+	)
+	return append([]models.Message{{
+		Role:    "system",
+		Content: fmt.Sprintf("Context from documentation: %s", context),
+	}}, messages...)
 }
 
-func deleteDocumentHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
-	}
-	id := c.Param("id")
-	
-	if err := st.db.Delete(&models.Document{}, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
-		return
-	}
-	
-	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+// logQuery stores a query for analytics. Failures are logged but never fail
+// the request.
+func (h *Handlers) logQuery(c *gin.Context, q *models.UserQuery) {
+	h.logQueryFor(scopeOf(c), q)
 }
 
-func popularQueriesHandler(c *gin.Context) {
-	st := getState()
-	if st == nil || st.db == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server not initialized"})
-		return
+func (h *Handlers) logQueryFor(sc requestScope, q *models.UserQuery) {
+	logging.For("api").DebugContext(sc.ctx, "query answered",
+		"kind", q.Kind, logging.KeyQuery, q.Query, logging.KeyResponse, q.Response)
+	if err := h.queries.LogQuery(sc.ctx, q); err != nil {
+		logging.For("api").ErrorContext(sc.ctx, "logging query failed", "kind", q.Kind, "error", err)
 	}
-	var queries []models.UserQuery
-	st.db.Order("created_at DESC").Limit(10).Find(&queries)
-	c.JSON(http.StatusOK, gin.H{"queries": queries})
 }
 
-func performSearch(query string, limit int) ([]models.SearchResult, error) {
-	st := getState()
-	if st == nil || st.cfg == nil || st.db == nil {
-		return nil, fmt.Errorf("server not initialized")
-	}
-	client := openai.NewClient(st.cfg.OpenAI.APIKey)
-	embeddingResp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
-		Input: query,
-		Model: openai.AdaEmbeddingV2,
-	})
-	if err != nil {
-		return nil, err
-	}
+// recordContext is the context for bookkeeping writes: it carries the
+// request's values but is not cancelled when the client goes away, since the
+// tokens were spent either way.
+func recordContext(c *gin.Context) context.Context {
+	return context.WithoutCancel(c.Request.Context())
+}
 
-	queryEmbedding := embeddingResp.Data[0].Embedding
+// requestScope is what work outliving a handler needs from its request;
+// gin recycles the *gin.Context once the handler returns.
+type requestScope struct {
+	ctx       context.Context // the request's values, never cancelled
+	path      string
+	principal usagePrincipal
+	endpoint  string // the usage endpoint answers are recorded under
+}
 
-	type row struct {
-		models.Document
-		Score float64 `gorm:"column:score" json:"score"`
+func scopeOf(c *gin.Context) requestScope {
+	return requestScope{
+		ctx:       recordContext(c),
+		path:      c.Request.URL.Path,
+		principal: principalFromContext(c),
 	}
-	var rows []row
-	err = st.db.Raw(`
-		SELECT d.*, e.vector <=> ? AS score
-		FROM documents d
-		JOIN embeddings e ON d.id = e.document_id
-		ORDER BY e.vector <=> ?
-		LIMIT ?
-	`, queryEmbedding, queryEmbedding, limit).Scan(&rows).Error
+}
 
+// embedDocument embeds content in the given space, chunked as configured,
+// and returns the vector along with the tokens billed for it.
+func (h *Handlers) embedDocument(ctx context.Context, space models.EmbeddingSpace, content string) (models.Vector, int, error) {
+	vectors, tokens, err := services.EmbedDocuments(ctx, h.embedder, space, []string{content}, h.cfg.Chunking)
 	if err != nil {
-		return nil, err
-	}
-	results := make([]models.SearchResult, 0, len(rows))
-	for _, r := range rows {
-		results = append(results, models.SearchResult{
-			Document: r.Document,
-			Score:    r.Score,
-		})
+		return nil, tokens, err
 	}
-	return results, nil
+	return models.Vector(vectors[0]), tokens, nil
 }
 
-func formatSearchContext(results []models.SearchResult) string {
-	var context strings.Builder
-	for i, result := range results {
-		contentPreview := result.Document.Content
-		if len(contentPreview) > 200 {
-			contentPreview = contentPreview[:200]
-		}
-		context.WriteString(fmt.Sprintf("Document %d: %s\n%s\n\n", 
-			i+1, result.Document.Title, contentPreview))
+// activeEmbeddingSpace resolves the space search runs against: the one set
+// by the last completed re-index, else the configured model.
+func (h *Handlers) activeEmbeddingSpace(ctx context.Context) (models.EmbeddingSpace, error) {
+	fallback, err := h.cfg.Providers.OpenAI.EmbeddingSpace()
+	if err != nil {
+		return models.EmbeddingSpace{}, err
 	}
-	return context.String()
+	return h.documents.ActiveEmbeddingSpace(ctx, fallback)
 }
 
 func convertMessages(messages []models.Message) []openai.ChatCompletionMessage {
@@ -403,6 +374,15 @@ func convertMessages(messages []models.Message) []openai.ChatCompletionMessage {
 	return openaiMessages
 }
 
+// chatModelName prefers the model the provider reports (which includes the
+// dated snapshot) over the alias we asked for.
+func chatModelName(reported, requested string) string {
+	if reported != "" {
+		return reported
+	}
+	return requested
+}
+
 func extractSourceURLs(results []models.SearchResult) []string {
 	urls := make([]string, 0, len(results))
 	for _, result := range results {
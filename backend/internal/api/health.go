@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/health"
+)
+
+// livezHandler only says the process is serving requests. It never touches
+// dependencies, so a database outage does not get the server restarted.
+func (h *Handlers) livezHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// readyzHandler reports whether the server can do useful work, with the
+// status of each dependency. It returns 503 when a critical check fails; a
+// failing provider only degrades it, since every replica would share that.
+func (h *Handlers) readyzHandler(c *gin.Context) {
+	report := health.Report{Status: health.StatusOK, Components: map[string]health.ComponentStatus{}}
+	if h.health != nil {
+		report = h.health.Run(c.Request.Context())
+	}
+	code := http.StatusOK
+	if !report.Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
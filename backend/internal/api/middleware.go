@@ -2,36 +2,134 @@
 package api
 
 import (
-	"net/http"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/metrics"
+)
+
+// Where the credential checks below store who the caller is, so handlers
+// can attribute work (e.g. usage accounting) to them.
+const (
+	contextUserIDKey     = "user_id"    // the token subject
+	contextAPIKeyIDKey   = "api_key_id" // the ID of the API key in auth.api_keys
+	contextCollectionKey = "collection" // from the API key, or the token's collection claim
 )
 
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		if e := verifyBearer(c, jwtSecret); e != nil {
+			abort(c, e)
 			return
 		}
+		c.Next()
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// TODO: validate signing method if you want to lock this down further
-			return []byte(jwtSecret), nil
-		})
+// IdentifyMiddleware attributes a request on the public routes to its
+// caller: a key from auth.api_keys sent as X-API-Key, a bearer token, or
+// both. Neither is required, but credentials that are sent must be valid, so
+// nobody can bill their usage to someone else.
+func IdentifyMiddleware(auth config.AuthConfig) gin.HandlerFunc {
+	keys := newAPIKeys(auth.APIKeys)
+	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			if e := keys.verify(c, key); e != nil {
+				abort(c, e)
+				return
+			}
+		}
+		if c.GetHeader("Authorization") != "" {
+			if e := verifyBearer(c, auth.JWTSecret); e != nil {
+				abort(c, e)
+				return
+			}
+		}
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
+// authNotConfigured refuses tokens when there is no secret: an empty one
+// would accept tokens signed with an empty key.
+func authNotConfigured() *apierror.Error {
+	return apierror.New(apierror.CodeAuthNotConfigured, "The server has no JWT secret configured.")
+}
+
+// verifyBearer checks the request's bearer token.
+func verifyBearer(c *gin.Context, jwtSecret string) *apierror.Error {
+	if jwtSecret == "" {
+		return authNotConfigured()
+	}
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return apierror.New(apierror.CodeUnauthorized, "An Authorization: Bearer token is required.")
+	}
+	return verifyToken(c, jwtSecret, strings.TrimPrefix(authHeader, "Bearer "))
+}
+
+// verifyToken checks a JWT and attributes the request to its subject and
+// collection claim.
+func verifyToken(c *gin.Context, jwtSecret, tokenString string) *apierror.Error {
+	if jwtSecret == "" {
+		return authNotConfigured()
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// TODO: validate signing method if you want to lock this down further
+		return []byte(jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return apierror.New(apierror.CodeUnauthorized, "The bearer token is invalid or expired.")
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if sub, _ := claims["sub"].(string); sub != "" {
+			c.Set(contextUserIDKey, sub)
 		}
+		if collection, _ := claims["collection"].(string); collection != "" && c.GetString(contextCollectionKey) == "" {
+			c.Set(contextCollectionKey, collection)
+		}
+	}
+	return nil
+}
 
-		c.Next()
+// apiKeys holds auth.api_keys by the SHA-256 of the key, so a lookup never
+// compares secrets byte by byte.
+type apiKeys map[[sha256.Size]byte]config.APIKeyConfig
+
+func newAPIKeys(keys []config.APIKeyConfig) apiKeys {
+	out := make(apiKeys, len(keys))
+	for _, k := range keys {
+		out[sha256.Sum256([]byte(k.Key))] = k
 	}
+	return out
+}
+
+func (k apiKeys) lookup(key string) (config.APIKeyConfig, bool) {
+	entry, ok := k[sha256.Sum256([]byte(key))]
+	return entry, ok
+}
+
+// verify checks key and attributes the request to it.
+func (k apiKeys) verify(c *gin.Context, key string) *apierror.Error {
+	entry, ok := k.lookup(key)
+	if !ok {
+		return apierror.New(apierror.CodeUnauthorized, "The API key is not recognised.")
+	}
+	c.Set(contextAPIKeyIDKey, entry.ID)
+	if entry.Collection != "" {
+		c.Set(contextCollectionKey, entry.Collection)
+	}
+	return nil
 }
 
 func CorsMiddleware(allowedOrigins []string) gin.HandlerFunc {
@@ -51,8 +149,9 @@ func CorsMiddleware(allowedOrigins []string) gin.HandlerFunc {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
 		}
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", 
-			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers",
+			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Collection, X-Request-ID, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", Deprecation, Sunset, Link, Location")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -63,3 +162,93 @@ func CorsMiddleware(allowedOrigins []string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// MetricsMiddleware times every request by its route template, so
+// /api/documents/1 and /api/documents/2 share a series. Unmatched paths are
+// grouped as "unmatched" to keep label cardinality bounded.
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.HTTPDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// RequestIDHeader carries the ID that ties a request to its log records.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware reuses the caller's X-Request-ID when it is a sane
+// token and generates one otherwise. The ID is echoed in the response and
+// attached to the request context for logging.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// AccessLogMiddleware logs one record per request to component "http".
+// The query string is left out when redacting, since it holds search text.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		path := c.Request.URL.Path
+		if raw := c.Request.URL.RawQuery; raw != "" && !logging.Redacting() {
+			path += "?" + raw
+		}
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		logging.For("http").Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
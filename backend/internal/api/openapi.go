@@ -0,0 +1,54 @@
+// backend/internal/api/openapi.go
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// openapiHandler serves the OpenAPI document, built once from the route
+// tables.
+func (h *Handlers) openapiHandler(c *gin.Context) {
+	specOnce.Do(func() {
+		specJSON, specErr = json.MarshalIndent(Spec(), "", "  ")
+	})
+	if specErr != nil {
+		abort(c, specErr)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", specJSON)
+}
+
+// swaggerUIHandler serves Swagger UI for /api/openapi.json. The UI itself is
+// loaded from a CDN.
+func swaggerUIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>AI Documentation Assistant API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+)
+
+// bucket is a token bucket refilled continuously at the configured rate.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	rate    float64 // tokens per second
+	burst   float64
+	swept   time.Time
+}
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*bucket),
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		swept:   time.Now(),
+	}
+}
+
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop idle clients every few minutes so the map does not grow forever.
+	if now.Sub(l.swept) > 5*time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > 10*time.Minute {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// RateLimitMiddleware limits each client (its API key once verified,
+// otherwise its IP) to cfg.RequestsPerMinute with bursts of cfg.Burst. It is
+// a no-op when rate limiting is disabled.
+func RateLimitMiddleware(cfg config.RateLimitConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	l := newRateLimiter(cfg.RequestsPerMinute, cfg.Burst)
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		if id := c.GetString(contextAPIKeyIDKey); id != "" {
+			key = "key:" + id
+		}
+		ok, wait := l.allow(key, time.Now())
+		if !ok {
+			e := apierror.New(apierror.CodeRateLimited, "Too many requests. Retry after the number of seconds in Retry-After.")
+			e.RetryAfterSeconds = int(wait.Seconds()) + 1
+			abort(c, e)
+			return
+		}
+		c.Next()
+	}
+}
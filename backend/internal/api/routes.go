@@ -1,43 +1,317 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/openapi"
 )
 
-func SetupRoutes(router *gin.Engine) {
-	st := getState()
-	_ = st // st is accessed indirectly by handlers; keep this for future auth wiring
+// route is an operation and the handler serving it. The route tables below
+// are the single source for the router, the OpenAPI document and the
+// generated client.
+type route struct {
+	openapi.Operation
+	handle func(*Handlers, *gin.Context)
+}
 
-	// Health check
-	router.GET("/health", healthCheckHandler)
+const (
+	v1Prefix    = "/api/v1"
+	v2Prefix    = "/api/v2"
+	adminPrefix = "/api/admin"
+)
+
+// v1Routes are mounted under /api/v1 and, for older clients, /api. They are
+// deprecated in favour of v2Routes and frozen.
+var v1Routes = []route{
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/health", ID: "Health", Tag: "health",
+		Summary:  "Report that the API is up",
+		Response: models.HealthResponse{},
+	}, (*Handlers).healthCheckHandler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/search", ID: "Search", Tag: "search",
+		Summary:     "Find the documents closest to a query",
+		Description: searchLimits,
+		Body:        models.SearchRequest{},
+		Response:    models.SearchResponse{},
+	}, (*Handlers).searchHandler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/chat", ID: "Chat", Tag: "chat",
+		Summary:  "Answer a conversation using the documentation; streamed like /chat/stream when asked",
+		Body:     models.ChatRequest{},
+		Response: models.ChatResponse{},
+		Stream:   true,
+	}, (*Handlers).chatHandler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/chat/stream", ID: "ChatStream", Tag: "chat",
+		Summary: "Stream an answer as server-sent events",
+		Body:    models.ChatRequest{},
+		Stream:  true,
+	}, (*Handlers).chatStreamHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/documents", ID: "ListDocuments", Tag: "documents",
+		Summary: "List documents a page at a time",
+		Params: []openapi.Param{
+			{Name: "page", Type: "integer", Description: "Page number, from 1."},
+			{Name: "limit", Type: "integer", Description: "Documents per page, clamped to 1 to 100 (default 10)."},
+		},
+		Response: models.DocumentListResponse{},
+	}, (*Handlers).listDocumentsHandler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/documents", ID: "CreateDocument", Tag: "documents",
+		Summary:  "Add a document and embed it",
+		Body:     models.Document{},
+		Response: models.Document{},
+		Status:   http.StatusCreated,
+	}, (*Handlers).createDocumentHandler},
+	{openapi.Operation{
+		Method: http.MethodDelete, Path: "/documents/:id", ID: "DeleteDocument", Tag: "documents",
+		Summary:  "Delete a document and its embeddings",
+		Response: models.MessageResponse{},
+	}, (*Handlers).deleteDocumentHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/popular", ID: "PopularQueries", Tag: "analytics",
+		Summary:  "List the most recent queries",
+		Response: models.PopularQueriesResponse{},
+	}, (*Handlers).popularQueriesHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/usage", ID: "UsageReport", Tag: "analytics",
+		Summary: "Report token usage and estimated cost per day",
+		Params: []openapi.Param{
+			{Name: "from", Type: "string", Format: "date", Description: "First day, YYYY-MM-DD (default 29 days before today)."},
+			{Name: "to", Type: "string", Format: "date", Description: "Last day, inclusive, YYYY-MM-DD (default today)."},
+			{Name: "group_by", Type: "string", Enum: []string{"user", "api_key", "collection"}, Description: "Principal to group by (default user)."},
+		},
+		Response: models.UsageReportResponse{},
+		Auth:     true,
+	}, (*Handlers).usageReportHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/embedding-cache", ID: "EmbeddingCacheStats", Tag: "analytics",
+		Summary:  "Report embedding cache hit rates",
+		Response: models.EmbeddingCacheResponse{},
+	}, (*Handlers).embeddingCacheStatsHandler},
+}
+
+// v2Routes are mounted under /api/v2.
+var v2Routes = []route{
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/health", ID: "HealthV2", Tag: "health",
+		Summary:  "Report that the API is up",
+		Response: models.HealthResponse{},
+	}, (*Handlers).healthCheckHandler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/search", ID: "SearchV2", Tag: "search",
+		Summary:     "Find the documents closest to a query",
+		Description: searchLimits,
+		Body:        models.SearchRequest{},
+		Response:    models.SearchResponseV2{},
+	}, (*Handlers).searchV2Handler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/chat", ID: "ChatV2", Tag: "chat",
+		Summary:  "Answer a conversation using the documentation, with its sources; streamed like /chat/stream when asked",
+		Body:     models.ChatRequestV2{},
+		Response: models.ChatResponseV2{},
+		Stream:   true,
+		Events:   streamEvents,
+	}, (*Handlers).chatV2Handler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/chat/stream", ID: "ChatStreamV2", Tag: "chat",
+		Summary: "Stream an answer as start, sources, delta, usage and done (or error) events",
+		Body:    models.ChatRequestV2{},
+		Stream:  true,
+		Events:  streamEvents,
+	}, (*Handlers).chatStreamV2Handler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/chat/stream/:stream_id", ID: "ResumeChatStreamV2", Tag: "chat",
+		Summary: "Replay a recent stream after the Last-Event-ID header, and follow it if it is still going",
+		Params: []openapi.Param{
+			{Name: "stream_id", In: "path", Type: "string", Description: "The id from the stream's start event."},
+			{Name: "last_event_id", Type: "string", Description: "Resume after this event ID, for clients that cannot set Last-Event-ID."},
+		},
+		Stream: true,
+		Events: streamEvents,
+	}, (*Handlers).resumeStreamV2Handler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/documents", ID: "ListDocumentsV2", Tag: "documents",
+		Summary: "List documents a page at a time",
+		Params: []openapi.Param{
+			{Name: "page", Type: "integer", Description: "Page number, from 1."},
+			{Name: "limit", Type: "integer", Description: "Documents per page, 1 to 100 (default 10)."},
+		},
+		Response: models.DocumentListResponseV2{},
+	}, (*Handlers).listDocumentsV2Handler},
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/documents", ID: "CreateDocumentV2", Tag: "documents",
+		Summary:  "Add a document and embed it",
+		Body:     models.DocumentInput{},
+		Response: models.Document{},
+		Status:   http.StatusCreated,
+	}, (*Handlers).createDocumentV2Handler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/documents/:id", ID: "GetDocumentV2", Tag: "documents",
+		Summary:  "Show one document",
+		Response: models.Document{},
+	}, (*Handlers).getDocumentV2Handler},
+	{openapi.Operation{
+		Method: http.MethodDelete, Path: "/documents/:id", ID: "DeleteDocumentV2", Tag: "documents",
+		Summary: "Delete a document and its embeddings",
+		Status:  http.StatusNoContent,
+	}, (*Handlers).deleteDocumentV2Handler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/popular", ID: "PopularQueriesV2", Tag: "analytics",
+		Summary:  "List the most recent queries",
+		Response: models.PopularQueriesResponse{},
+	}, (*Handlers).popularQueriesHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/usage", ID: "UsageReportV2", Tag: "analytics",
+		Summary: "Report token usage and estimated cost per day",
+		Params: []openapi.Param{
+			{Name: "from", Type: "string", Format: "date", Description: "First day, YYYY-MM-DD (default 29 days before today)."},
+			{Name: "to", Type: "string", Format: "date", Description: "Last day, inclusive, YYYY-MM-DD (default today)."},
+			{Name: "group_by", Type: "string", Enum: []string{"user", "api_key", "collection"}, Description: "Principal to group by (default user)."},
+		},
+		Response: models.UsageReportResponse{},
+		Auth:     true,
+	}, (*Handlers).usageReportHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/analytics/embedding-cache", ID: "EmbeddingCacheStatsV2", Tag: "analytics",
+		Summary:  "Report embedding cache hit rates",
+		Response: models.EmbeddingCacheResponse{},
+	}, (*Handlers).embeddingCacheStatsHandler},
+}
+
+// streamEvents are the events of a v2 chat stream.
+var streamEvents = []openapi.Event{
+	{Name: eventStart, Data: models.StreamStart{}},
+	{Name: eventSources, Data: models.StreamSources{}},
+	{Name: eventDelta, Data: models.StreamDelta{}},
+	{Name: eventUsage, Data: models.TokenUsage{}},
+	{Name: eventError, Data: apierror.Problem{}},
+	{Name: eventDone, Data: models.StreamDone{}},
+}
+
+// searchLimits documents the limit rules runSearch enforces.
+const searchLimits = "limit defaults to retrieval.default_limit. A limit above retrieval.max_limit is refused with invalid_request, not lowered."
+
+// adminRoutes are mounted under /api/admin behind a bearer token.
+var adminRoutes = []route{
+	{openapi.Operation{
+		Method: http.MethodPost, Path: "/reindex", ID: "StartReindex", Tag: "admin",
+		Summary:  "Start or resume re-embedding the corpus into a new embedding space",
+		Body:     models.ReindexRequest{},
+		Response: models.ReindexJob{},
+		Status:   http.StatusAccepted,
+		Auth:     true,
+	}, (*Handlers).startReindexHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/reindex", ID: "ListReindexJobs", Tag: "admin",
+		Summary:  "List recent re-index jobs",
+		Response: models.ReindexJobListResponse{},
+		Auth:     true,
+	}, (*Handlers).listReindexJobsHandler},
+	{openapi.Operation{
+		Method: http.MethodGet, Path: "/reindex/:id", ID: "GetReindexJob", Tag: "admin",
+		Summary:  "Show one re-index job",
+		Response: models.ReindexJob{},
+		Auth:     true,
+	}, (*Handlers).getReindexJobHandler},
+}
+
+// Operations lists every documented operation with its full path. The v1
+// operations are marked deprecated.
+func Operations() []openapi.Operation {
+	var ops []openapi.Operation
+	add := func(prefix string, routes []route, deprecated bool) {
+		for _, r := range routes {
+			op := r.Operation
+			op.Path = prefix + op.Path
+			op.Deprecated = deprecated
+			ops = append(ops, op)
+		}
+	}
+	add(v1Prefix, v1Routes, true)
+	add(v2Prefix, v2Routes, false)
+	add(adminPrefix, adminRoutes, false)
+	return ops
+}
 
-	// API routes (matches your local dev guide)
-	api := router.Group("/api")
-	{
-		api.GET("/health", healthCheckHandler)
+// Spec is the OpenAPI document served on /api/openapi.json.
+func Spec() map[string]any {
+	return openapi.Build(openapi.Info{
+		Title:   "AI Documentation Assistant API",
+		Version: "2.0.0",
+		Description: "Semantic search and retrieval-augmented chat over documentation. " +
+			"/api/v2 is current. /api/v1, also served without the version segment, is " +
+			"deprecated: its responses carry Deprecation, Sunset and Link headers. " +
+			"Errors are RFC 7807 problem documents.",
+	}, apierror.Problem{}, Operations())
+}
 
-		api.POST("/search", searchHandler)
-		api.POST("/chat", chatHandler)
-		api.POST("/chat/stream", chatStreamHandler)
+// mount adds routes to group. Routes marked Auth need a bearer token
+// whatever the group allows.
+func (h *Handlers) mount(group *gin.RouterGroup, routes []route) {
+	auth := AuthMiddleware(h.cfg.Auth.JWTSecret)
+	for _, r := range routes {
+		handle := r.handle
+		serve := func(c *gin.Context) { handle(h, c) }
+		if r.Auth {
+			group.Handle(r.Method, r.Path, auth, serve)
+		} else {
+			group.Handle(r.Method, r.Path, serve)
+		}
+	}
+}
 
-		// NOTE: No auth flow is implemented yet (no login/token issuance).
-		// Keep these public for now so local dev works end-to-end.
-		api.GET("/documents", listDocumentsHandler)
-		api.POST("/documents", createDocumentHandler)
-		api.DELETE("/documents/:id", deleteDocumentHandler)
+// RegisterRoutes mounts every endpoint on router.
+func (h *Handlers) RegisterRoutes(router *gin.Engine) {
+	limiter := RateLimitMiddleware(h.cfg.RateLimit)
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(noRoute)
+	router.NoMethod(noMethod)
 
-		api.GET("/analytics/popular", popularQueriesHandler)
+	// Health check
+	router.GET("/health", h.healthCheckHandler)
+	// Probes for orchestrators: is the process up, and can it serve?
+	router.GET("/livez", h.livezHandler)
+	router.GET("/readyz", h.readyzHandler)
+	if h.metrics != nil {
+		router.GET("/metrics", gin.WrapH(h.metrics.Handler()))
 	}
 
-	// Back-compat (older frontend/docker compose)
-	v1 := router.Group("/api/v1")
-	{
-		v1.POST("/search", searchHandler)
-		v1.POST("/chat", chatHandler)
-		v1.POST("/chat/stream", chatStreamHandler)
-		v1.GET("/documents", listDocumentsHandler)
-		v1.POST("/documents", createDocumentHandler)
-		v1.DELETE("/documents/:id", deleteDocumentHandler)
-		v1.GET("/analytics/popular", popularQueriesHandler)
+	// The API description and a browser for it.
+	router.GET("/api/openapi.json", h.openapiHandler)
+	router.GET("/api/docs", swaggerUIHandler)
+
+	// NOTE: No auth flow is implemented yet (no login/token issuance).
+	// Keep the public routes open for now so local dev works end-to-end;
+	// credentials are only checked when sent, to attribute usage.
+	identify := IdentifyMiddleware(h.cfg.Auth)
+	h.mount(router.Group(v2Prefix, identify, limiter), v2Routes)
+	// Legacy: v1, and the same routes without the version segment for older
+	// frontends and docker compose setups.
+	sunset, _ := h.cfg.Server.LegacySunset() // checked by Config.Validate
+	h.mount(router.Group(v1Prefix, identify, limiter, legacyAPI(v1Prefix, sunset)), v1Routes)
+	h.mount(router.Group("/api", identify, limiter, legacyAPI("/api", sunset)), v1Routes)
+
+	// The OpenAI API, for tools built on OpenAI SDKs. It follows OpenAI's
+	// shapes rather than this API's, so it is not in the OpenAPI document.
+	// It spends the provider key on the caller's behalf, so it always needs
+	// an API key or token.
+	compat := router.Group("/v1", compatAuth(h.cfg.Auth), limiter)
+	compat.GET("/models", h.listModelsHandler)
+	compat.POST("/chat/completions", h.chatCompletionsHandler)
+	compat.POST("/embeddings", h.embeddingsHandler)
+
+	// Chat over a WebSocket, for the frontend. It holds a connection and
+	// can run up the model bill, so it needs a token like the admin API.
+	router.GET("/api/ws", limiter, wsToken(), AuthMiddleware(h.cfg.Auth.JWTSecret), h.websocketHandler)
+
+	// Admin operations are expensive and always require a token (they are
+	// all marked Auth). They need somewhere to keep re-index jobs.
+	if h.reindex != nil {
+		h.mount(router.Group(adminPrefix, limiter), adminRoutes)
 	}
 }
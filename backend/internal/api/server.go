@@ -1,34 +1,302 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
 	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/health"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/metrics"
+	"github.com/yourname/ai-documentation-assistant/internal/migrate"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+	"github.com/yourname/ai-documentation-assistant/migrations"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 )
 
+// Clock tells the handlers the time, so tests can fix it.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Searcher retrieves the documents relevant to a query.
+type Searcher interface {
+	Search(ctx context.Context, q services.SearchQuery) (services.Retrieval, error)
+}
+
+// Deps is everything the handlers use. Config, Documents, Queries, Embedder,
+// Search and Chat are required; the rest are optional.
+type Deps struct {
+	Config    *config.Config
+	Documents store.DocumentStore
+	Queries   store.QueryLogStore
+	Embedder  services.Embedder
+	Search    Searcher
+	Chat      services.ChatProvider
+	// Clock defaults to the system clock.
+	Clock Clock
+
+	// Reindex backs the admin (re-index) endpoints, which are only mounted
+	// when it is set.
+	Reindex store.ReindexStore
+	// EmbeddingCache is reported on /analytics/embedding-cache; nil when
+	// disabled.
+	EmbeddingCache *services.EmbeddingCache
+	// Answers, when set, lets chat reuse earlier answers.
+	Answers *services.AnswerCache
+	// Health runs the /readyz checks; without it the server is always
+	// reported ready.
+	Health *health.Monitor
+	// Metrics, when set, is served on /metrics and fed by every request.
+	// Instrument the Embedder, Chat and Search deps with it as well.
+	Metrics *metrics.Metrics
+}
+
+// DefaultDeps wires the production implementations over db.
+func DefaultDeps(cfg *config.Config, db *gorm.DB) (Deps, error) {
+	fallback, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	if err != nil {
+		return Deps{}, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return Deps{}, err
+	}
+	migs, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return Deps{}, err
+	}
+	client := services.NewOpenAIClient(cfg.Providers.OpenAI)
+	monitor := health.NewMonitor(
+		health.Database(sqlDB),
+		health.PGVector(sqlDB),
+		health.Migrations(sqlDB, migrate.NewRunner(sqlDB, migs).Latest()),
+		health.OpenAIModel("embedding_provider", client, fallback.Model),
+		health.OpenAIModel("chat_provider", client, cfg.Providers.OpenAI.ChatModel),
+	)
+
+	m := metrics.New()
+	m.WatchDB(sqlDB)
+	pg := store.NewPostgres(db)
+	m.WatchReindexJobs(pg)
+
+	cache := services.NewEmbeddingCache(db, cfg.Cache.Embeddings)
+	embedder := m.Embedder(services.NewOpenAIEmbedder(cfg.Providers.OpenAI, cache))
+	return Deps{
+		Config:         cfg,
+		Documents:      pg,
+		Queries:        pg,
+		Embedder:       embedder,
+		Search:         m.Searcher(services.NewSearchService(pg, embedder, fallback, cfg.Retrieval)),
+		Chat:           m.ChatProvider(services.NewOpenAIChat(cfg.Providers.OpenAI)),
+		Reindex:        pg,
+		EmbeddingCache: cache,
+		Answers:        services.NewAnswerCache(pg, cfg.Cache.Answers),
+		Health:         monitor,
+		Metrics:        m,
+	}, nil
+}
+
+// Handlers serves the API from its dependencies. It holds no package-level
+// state, so any number can coexist in one process.
+type Handlers struct {
+	cfg            *config.Config
+	documents      store.DocumentStore
+	queries        store.QueryLogStore
+	embedder       services.Embedder
+	search         Searcher
+	chat           services.ChatProvider
+	clock          Clock
+	reindex        store.ReindexStore
+	embeddingCache *services.EmbeddingCache
+	answers        *services.AnswerCache
+	health         *health.Monitor
+	metrics        *metrics.Metrics
+	streams        *streamHub
+
+	// jobs is the context for background work a request starts but does not
+	// wait for (re-indexing). It is cancelled by Shutdown. running also
+	// counts chat answers being generated, which finish on their own.
+	jobs     context.Context
+	stopJobs context.CancelFunc
+	running  sync.WaitGroup
+}
+
+// NewHandlers checks deps and builds the handlers.
+func NewHandlers(deps Deps) (*Handlers, error) {
+	switch {
+	case deps.Config == nil:
+		return nil, errors.New("api: Config is required")
+	case deps.Documents == nil:
+		return nil, errors.New("api: Documents is required")
+	case deps.Queries == nil:
+		return nil, errors.New("api: Queries is required")
+	case deps.Embedder == nil:
+		return nil, errors.New("api: Embedder is required")
+	case deps.Search == nil:
+		return nil, errors.New("api: Search is required")
+	case deps.Chat == nil:
+		return nil, errors.New("api: Chat is required")
+	}
+	if deps.Clock == nil {
+		deps.Clock = systemClock{}
+	}
+	jobs, stopJobs := context.WithCancel(context.Background())
+	return &Handlers{
+		cfg:            deps.Config,
+		documents:      deps.Documents,
+		queries:        deps.Queries,
+		embedder:       deps.Embedder,
+		search:         deps.Search,
+		chat:           services.TracedChat(deps.Chat),
+		clock:          deps.Clock,
+		reindex:        deps.Reindex,
+		embeddingCache: deps.EmbeddingCache,
+		answers:        deps.Answers,
+		health:         deps.Health,
+		metrics:        deps.Metrics,
+		streams:        newStreamHub(),
+		jobs:           jobs,
+		stopJobs:       stopJobs,
+	}, nil
+}
+
+// goBackground runs fn under the jobs context and tracks it for Shutdown.
+func (h *Handlers) goBackground(fn func(ctx context.Context)) {
+	h.running.Add(1)
+	go func() {
+		defer h.running.Done()
+		fn(h.jobs)
+	}()
+}
+
+// Shutdown cancels background jobs and waits for them to wind down (a
+// re-index pauses and saves its cursor) or for ctx to expire.
+func (h *Handlers) Shutdown(ctx context.Context) error {
+	h.stopJobs()
+	done := make(chan struct{})
+	go func() {
+		h.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type Server struct {
-	config *config.Config
-	db     *gorm.DB
-	router *gin.Engine
+	config   *config.Config
+	router   *gin.Engine
+	handlers *Handlers
 }
 
-func NewServer(cfg *config.Config, db *gorm.DB) *Server {
-	if cfg.Environment == "production" {
+// NewServer builds the router with its middleware and every route.
+func NewServer(deps Deps) (*Server, error) {
+	h, err := NewHandlers(deps)
+	if err != nil {
+		return nil, err
+	}
+	if deps.Config.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
-	r.Use(CorsMiddleware(cfg.Security.CORS))
+	r.Use(RequestIDMiddleware())
+	r.Use(AccessLogMiddleware())
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, logPanic))
+	r.Use(otelgin.Middleware(deps.Config.Tracing.ServiceName, otelgin.WithFilter(traced)))
+	if deps.Metrics != nil {
+		r.Use(MetricsMiddleware(deps.Metrics))
+	}
+	r.Use(CorsMiddleware(deps.Config.Server.CORSOrigins))
+	h.RegisterRoutes(r)
+
+	return &Server{config: deps.Config, router: r, handlers: h}, nil
+}
+
+// logPanic reports a recovered handler panic with its stack.
+func logPanic(c *gin.Context, err any) {
+	logging.For("http").ErrorContext(c.Request.Context(), "panic serving request",
+		"error", fmt.Sprint(err), "stack", string(debug.Stack()))
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+	abort(c, apierror.New(apierror.CodeInternal, "An unexpected error occurred."))
+}
 
-	// Wire deps and register routes (keeps this server usable in tests/alt entrypoints)
-	Init(cfg, db)
-	SetupRoutes(r)
+// traced leaves probe and scrape requests out of traces.
+func traced(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/health", "/livez", "/readyz", "/metrics":
+		return false
+	}
+	return true
+}
 
-	return &Server{config: cfg, db: db, router: r}
+// Handler returns the server's router, e.g. for httptest.
+func (s *Server) Handler() http.Handler {
+	return s.router
 }
 
-func (s *Server) Start() error {
-	return s.router.Run(":" + s.config.Port)
+// Run serves until ctx is cancelled (e.g. on SIGTERM), then stops accepting
+// connections and gives in-flight requests, streams and background jobs
+// server.shutdown_timeout_seconds to finish. Whatever is still running after
+// that is cut off; its request context is cancelled, which stops any
+// provider calls it was making.
+func (s *Server) Run(ctx context.Context) error {
+	cfg := s.config.Server
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           s.router,
+		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		s.handlers.stopJobs()
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	logging.For("app").Info("shutting down", "drain_timeout", timeout.String())
+	drain, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var jobsErr error
+	jobsDone := make(chan struct{})
+	go func() {
+		jobsErr = s.handlers.Shutdown(drain)
+		close(jobsDone)
+	}()
+	if err := srv.Shutdown(drain); err != nil {
+		logging.For("app").Warn("drain timed out; closing remaining connections", "error", err)
+		srv.Close()
+	}
+	<-jobsDone
+	if jobsErr != nil {
+		logging.For("app").Warn("background jobs did not stop in time", "error", jobsErr)
+	}
+	return nil
 }
@@ -0,0 +1,417 @@
+// backend/internal/api/stream.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+)
+
+// Chat answers stream as server-sent events. The answer is generated in its
+// own goroutine into a chatStream, an append-only event log, and the request
+// that started it (or one resuming it with Last-Event-ID) relays the log to
+// its client. v2 sends every event, each with an ID; v1 keeps its original
+// data-only framing.
+
+// Stream events, in the order they are sent. error replaces usage and done
+// when generation fails part way.
+const (
+	eventStart   = "start"   // models.StreamStart
+	eventSources = "sources" // models.StreamSources
+	eventDelta   = "delta"   // models.StreamDelta
+	eventUsage   = "usage"   // models.TokenUsage
+	eventError   = "error"   // apierror.Problem
+	eventDone    = "done"    // models.StreamDone
+)
+
+type sseEvent struct {
+	Seq  int // from 1 within a stream
+	Name string
+	Data any
+}
+
+// chatStream is one answer's events. Any number of requests can watch it;
+// when the last one leaves before it is done, generation is cancelled,
+// after a grace period if the stream can be resumed.
+type chatStream struct {
+	id     string
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	events   []sseEvent
+	done     bool
+	changed  chan struct{} // closed and replaced whenever the log grows or ends
+	watchers int
+	orphaned *time.Timer
+}
+
+func newChatStream(cancel context.CancelFunc) *chatStream {
+	return &chatStream{id: newRequestID(), cancel: cancel, changed: make(chan struct{})}
+}
+
+func (s *chatStream) emit(name string, data any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, sseEvent{Seq: len(s.events) + 1, Name: name, Data: data})
+	s.wake()
+}
+
+func (s *chatStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if s.orphaned != nil {
+		s.orphaned.Stop()
+	}
+	s.wake()
+}
+
+// wake is called with mu held.
+func (s *chatStream) wake() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// since returns the events after seq, whether that is all of them, and a
+// channel that is closed when there are more.
+func (s *chatStream) since(seq int) ([]sseEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq = min(max(seq, 0), len(s.events))
+	return s.events[seq:], s.done, s.changed
+}
+
+func (s *chatStream) attach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers++
+	if s.orphaned != nil {
+		s.orphaned.Stop()
+		s.orphaned = nil
+	}
+}
+
+// detach drops a watcher. If it was the last and the answer is unfinished,
+// generation is cancelled once grace passes without another attaching.
+func (s *chatStream) detach(grace time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers--
+	if s.watchers > 0 || s.done {
+		return
+	}
+	if grace <= 0 {
+		s.cancel()
+		return
+	}
+	s.orphaned = time.AfterFunc(grace, s.cancel)
+}
+
+// streamHub holds the streams that can be resumed, by ID.
+type streamHub struct {
+	mu      sync.Mutex
+	streams map[string]*chatStream
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{streams: map[string]*chatStream{}}
+}
+
+func (hub *streamHub) add(s *chatStream) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.streams[s.id] = s
+}
+
+func (hub *streamHub) get(id string) (*chatStream, bool) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	s, ok := hub.streams[id]
+	return s, ok
+}
+
+// retire forgets s once retain has passed.
+func (hub *streamHub) retire(s *chatStream, retain time.Duration) {
+	time.AfterFunc(retain, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		delete(hub.streams, s.id)
+	})
+}
+
+// resumeWindow is how long a stream can be resumed; 0 when it cannot.
+func (h *Handlers) resumeWindow() time.Duration {
+	return time.Duration(h.cfg.Server.StreamResumeSeconds) * time.Second
+}
+
+// streamFramer writes one event in an API version's wire format.
+type streamFramer func(w io.Writer, streamID string, e sseEvent) error
+
+// frameV2 writes e with an ID of the form <stream>:<seq>. The data is JSON,
+// which never contains a raw newline, so it is always a single data line.
+func frameV2(w io.Writer, streamID string, e sseEvent) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s:%d\nevent: %s\ndata: %s\n\n", streamID, e.Seq, e.Name, data)
+	return err
+}
+
+// frameV1 keeps the original protocol: data-only {"content": ...} events,
+// then data: [DONE], or an "error" event carrying the problem.
+func frameV1(w io.Writer, _ string, e sseEvent) error {
+	var err error
+	switch e.Name {
+	case eventDelta:
+		var data []byte
+		if data, err = json.Marshal(e.Data); err == nil {
+			_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+	case eventError:
+		var data []byte
+		if data, err = json.Marshal(e.Data); err == nil {
+			_, err = fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+		}
+	case eventDone:
+		_, err = io.WriteString(w, "data: [DONE]\n\n")
+	}
+	return err
+}
+
+// streamRequested reports whether a /chat request wants its answer streamed:
+// "stream": true, or an Accept header that ranks text/event-stream at least
+// as high as application/json. Either way /chat then behaves exactly like
+// /chat/stream.
+func streamRequested(c *gin.Context, stream bool) bool {
+	c.Header("Vary", "Accept")
+	if stream {
+		return true
+	}
+	sse, plain := acceptQuality(c.GetHeader("Accept"))
+	return sse > 0 && sse >= plain
+}
+
+// acceptQuality returns the q-values Accept gives text/event-stream and
+// application/json, 0 for those it does not list.
+func acceptQuality(accept string) (eventStream, applicationJSON float64) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/event-stream":
+			eventStream = q
+		case "application/json":
+			applicationJSON = q
+		}
+	}
+	return eventStream, applicationJSON
+}
+
+// startStream answers messages as a stream framed by frame. A resumable
+// stream outlives its request for the resume window; otherwise generation
+// stops as soon as the client goes away.
+func (h *Handlers) startStream(c *gin.Context, messages []models.Message, resumable bool, frame streamFramer) {
+	sc := scopeOf(c)
+	sc.endpoint = "chat_stream"
+	grace := time.Duration(0)
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	if resumable && h.resumeWindow() > 0 {
+		grace = h.resumeWindow()
+		cancel()
+		ctx, cancel = context.WithCancel(sc.ctx)
+	}
+
+	pending, err := h.openAnswer(ctx, sc, messages)
+	if err != nil {
+		cancel()
+		abort(c, err)
+		return
+	}
+
+	s := newChatStream(cancel)
+	if grace > 0 {
+		h.streams.add(s)
+	}
+	s.attach()
+	// Tracked like background jobs, so Shutdown waits for the answer.
+	h.running.Add(1)
+	go func() {
+		defer h.running.Done()
+		defer cancel()
+		h.generate(sc, s, pending)
+		if grace > 0 {
+			h.streams.retire(s, grace)
+		}
+	}()
+
+	h.relay(c, s, 0, frame)
+	s.detach(grace)
+}
+
+// resumeStreamV2Handler replays a v2 stream from after the Last-Event-ID
+// header (or last_event_id parameter) and follows it if it is still going.
+func (h *Handlers) resumeStreamV2Handler(c *gin.Context) {
+	s, ok := h.streams.get(c.Param("stream_id"))
+	if !ok {
+		abort(c, apierror.New(apierror.CodeNotFound, "The stream does not exist or can no longer be resumed."))
+		return
+	}
+	seq := 0
+	last := c.GetHeader("Last-Event-ID")
+	if last == "" {
+		last = c.Query("last_event_id")
+	}
+	if last != "" {
+		id, n, _ := strings.Cut(last, ":")
+		var err error
+		if seq, err = strconv.Atoi(n); id != s.id || err != nil || seq < 0 {
+			invalid(c, "Last-Event-ID %q is not an event of this stream", last)
+			return
+		}
+	}
+
+	s.attach()
+	h.relay(c, s, seq, frameV2)
+	s.detach(h.resumeWindow())
+}
+
+// relay writes the events of s after seq to the client until the stream
+// ends or the client goes away, with a keep-alive comment every
+// server.stream_keepalive_seconds.
+func (h *Handlers) relay(c *gin.Context, s *chatStream, seq int, frame streamFramer) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx would otherwise hold events back
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(time.Duration(h.cfg.Server.StreamKeepAliveSeconds) * time.Second)
+	defer keepAlive.Stop()
+	for {
+		events, done, changed := s.since(seq)
+		for _, e := range events {
+			if err := frame(c.Writer, s.id, e); err != nil {
+				return
+			}
+			seq = e.Seq
+		}
+		c.Writer.Flush()
+		if done {
+			return
+		}
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// pendingAnswer is a completion stream that has been opened but not read.
+type pendingAnswer struct {
+	stream services.ChatStream
+	grounding
+}
+
+// openAnswer retrieves context for messages and opens the completion stream
+// under ctx. Nothing has been sent when it fails, so the failure can still
+// be an ordinary problem response.
+func (h *Handlers) openAnswer(ctx context.Context, sc requestScope, messages []models.Message) (*pendingAnswer, error) {
+	g, err := h.retrieveContext(ctx, sc, messages)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := h.completionStream(ctx, g, openai.ChatCompletionRequest{
+		Model:       h.cfg.Providers.OpenAI.ChatModel,
+		Messages:    convertMessages(messages),
+		Temperature: h.cfg.Providers.OpenAI.Temperature,
+		MaxTokens:   h.cfg.Providers.OpenAI.MaxTokens,
+		Stream:      true,
+		// Ask for a final usage chunk so streamed answers can be accounted for.
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		h.recordAnswerUsage(sc, g, models.UsageEvent{})
+		return nil, err
+	}
+	return &pendingAnswer{stream: stream, grounding: g}, nil
+}
+
+// generate reads the completion into s, accounts for it and ends s.
+func (h *Handlers) generate(sc requestScope, s *chatStream, p *pendingAnswer) {
+	defer s.finish()
+	defer p.stream.Close()
+
+	s.emit(eventStart, models.StreamStart{ID: s.id, Model: h.cfg.Providers.OpenAI.ChatModel})
+	s.emit(eventSources, models.StreamSources{Sources: h.hits(p.sources)})
+
+	var answer strings.Builder
+	finishReason := ""
+	usage := models.UsageEvent{ChatModel: h.cfg.Providers.OpenAI.ChatModel}
+	for {
+		chunk, err := p.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			h.recordAnswerUsage(sc, p.grounding, usage)
+			e := classify(sc.ctx, err)
+			s.emit(eventError, e.Problem(sc.path, logging.RequestID(sc.ctx)))
+			return
+		}
+		if chunk.Usage != nil {
+			usage.ChatModel = chatModelName(chunk.Model, h.cfg.Providers.OpenAI.ChatModel)
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) > 0 {
+			choice := chunk.Choices[0]
+			if choice.Delta.Content != "" {
+				s.emit(eventDelta, models.StreamDelta{Content: choice.Delta.Content})
+				answer.WriteString(choice.Delta.Content)
+			}
+			if choice.FinishReason != "" {
+				finishReason = string(choice.FinishReason)
+			}
+		}
+	}
+
+	h.logAnswer(sc, p.grounding, answer.String())
+	h.recordAnswerUsage(sc, p.grounding, usage)
+	s.emit(eventUsage, models.TokenUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		EmbeddingTokens:  p.retrieval.EmbeddingTokens,
+	})
+	s.emit(eventDone, models.StreamDone{FinishReason: finishReason})
+}
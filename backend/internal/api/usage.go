@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// usagePrincipal identifies who a request should be billed to.
+type usagePrincipal struct {
+	UserID     string
+	APIKeyID   string
+	Collection string
+}
+
+// principalFromContext collects attribution for the current request from
+// the credentials IdentifyMiddleware, AuthMiddleware or compatAuth verified.
+// Nothing a caller can simply claim in a header is used.
+func principalFromContext(c *gin.Context) usagePrincipal {
+	return usagePrincipal{
+		UserID:     c.GetString(contextUserIDKey),
+		APIKeyID:   c.GetString(contextAPIKeyIDKey),
+		Collection: c.GetString(contextCollectionKey),
+	}
+}
+
+// recordUsage stores a usage event. Failures are logged but never fail the
+// request that consumed the tokens.
+func (h *Handlers) recordUsage(c *gin.Context, ev models.UsageEvent) {
+	h.recordUsageFor(scopeOf(c), ev)
+}
+
+func (h *Handlers) recordUsageFor(sc requestScope, ev models.UsageEvent) {
+	ev.UserID = sc.principal.UserID
+	ev.APIKeyID = sc.principal.APIKeyID
+	ev.Collection = sc.principal.Collection
+	ev.CostUSD = services.EstimateCost(ev.ChatModel, ev.PromptTokens, ev.CompletionTokens) +
+		services.EstimateCost(ev.EmbeddingModel, ev.EmbeddingTokens, 0)
+	if err := h.queries.RecordUsage(sc.ctx, &ev); err != nil {
+		logging.For("api").ErrorContext(sc.ctx, "recording usage failed", "endpoint", ev.Endpoint, "error", err)
+	}
+}
+
+func (h *Handlers) usageReportHandler(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "user")
+	if !store.ValidUsageGroup(groupBy) {
+		invalid(c, "group_by must be one of user, api_key, collection")
+		return
+	}
+
+	// Default to the last 30 days; "to" is inclusive of the whole day.
+	today := h.clock.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -29)
+	to := today
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			invalid(c, "from must be YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			invalid(c, "to must be YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		invalid(c, "to must not be before from")
+		return
+	}
+
+	rows, err := h.queries.UsageReport(c.Request.Context(), groupBy, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		abort(c, apierror.Database(err, "Failed to load usage."))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UsageReportResponse{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Rows:    rows,
+	})
+}
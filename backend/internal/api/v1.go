@@ -0,0 +1,116 @@
+// backend/internal/api/v1.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+// The v1 handlers adapt the shared implementation to the original response
+// shapes. v1 is frozen: new fields and endpoints go into v2 only.
+
+// v1DeprecatedAt is when v2 shipped and v1 became deprecated.
+var v1DeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// legacyAPI marks every response under prefix as deprecated: Deprecation
+// (RFC 9745), Sunset (RFC 8594) when a date is configured, and a Link to the
+// v2 equivalent.
+func legacyAPI(prefix string, sunset time.Time) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", v1DeprecatedAt.Unix())
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.Format(http.TimeFormat))
+		}
+		successor := v2Prefix + strings.TrimPrefix(c.Request.URL.Path, prefix)
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		c.Next()
+	}
+}
+
+func (h *Handlers) searchHandler(c *gin.Context) {
+	var req models.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	results, err := h.runSearch(c, req)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.SearchResponse{
+		Results: results,
+		Query:   req.Query,
+		Count:   len(results),
+	})
+}
+
+func (h *Handlers) chatHandler(c *gin.Context) {
+	var req models.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	if streamRequested(c, req.Stream) {
+		h.startStream(c, req.Messages, false, frameV1)
+		return
+	}
+	reply, err := h.answer(c, req.Messages)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	// v1 never returned the sources, only whether the answer was reused.
+	c.JSON(http.StatusOK, models.ChatResponse{Message: reply.Message, Cached: reply.Cached})
+}
+
+func (h *Handlers) chatStreamHandler(c *gin.Context) {
+	var req models.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	h.startStream(c, req.Messages, false, frameV1)
+}
+
+func (h *Handlers) listDocumentsHandler(c *gin.Context) {
+	n, limit := lenientPageParams(c)
+	p, err := h.listDocuments(c, n, limit)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.DocumentListResponse{
+		Documents: p.Documents,
+		Total:     p.Total,
+		Page:      p.Page,
+		Limit:     p.Limit,
+	})
+}
+
+func (h *Handlers) createDocumentHandler(c *gin.Context) {
+	var doc models.Document
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	if err := h.createDocument(c, &doc); err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, doc)
+}
+
+func (h *Handlers) deleteDocumentHandler(c *gin.Context) {
+	if err := h.deleteDocument(c); err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Document deleted"})
+}
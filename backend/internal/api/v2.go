@@ -0,0 +1,154 @@
+// backend/internal/api/v2.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// The v2 handlers return the typed shapes in models (SearchResponseV2,
+// ChatResponseV2, ...). Breaking changes to them need a v3.
+
+func (h *Handlers) searchV2Handler(c *gin.Context) {
+	var req models.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	results, err := h.runSearch(c, req)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.SearchResponseV2{Query: req.Query, Results: h.hits(results)})
+}
+
+func (h *Handlers) chatV2Handler(c *gin.Context) {
+	var req models.ChatRequestV2
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	if streamRequested(c, req.Stream) {
+		h.startStream(c, req.Messages, true, frameV2)
+		return
+	}
+	reply, err := h.answer(c, req.Messages)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.ChatResponseV2{
+		Message: models.Message{Role: "assistant", Content: reply.Message},
+		Sources: h.hits(reply.Sources),
+		Cached:  reply.Cached,
+		Usage:   reply.Usage,
+	})
+}
+
+func (h *Handlers) chatStreamV2Handler(c *gin.Context) {
+	var req models.ChatRequestV2
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	h.startStream(c, req.Messages, true, frameV2)
+}
+
+func (h *Handlers) listDocumentsV2Handler(c *gin.Context) {
+	n, limit, err := pageParams(c)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	p, err := h.listDocuments(c, n, limit)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.DocumentListResponseV2{
+		Documents: p.Documents,
+		Pagination: models.Pagination{
+			Page:    p.Page,
+			Limit:   p.Limit,
+			Total:   p.Total,
+			HasMore: int64(p.Page*p.Limit) < p.Total,
+		},
+	})
+}
+
+func (h *Handlers) getDocumentV2Handler(c *gin.Context) {
+	id, err := documentID(c)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	doc, err := h.documents.GetDocument(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		abort(c, apierror.Newf(apierror.CodeNotFound, "Document %d does not exist.", id))
+		return
+	}
+	if err != nil {
+		abort(c, apierror.Database(err, "Failed to load document."))
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
+
+func (h *Handlers) createDocumentV2Handler(c *gin.Context) {
+	var in models.DocumentInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		invalid(c, "%v", err)
+		return
+	}
+	doc := models.Document{
+		Title:    in.Title,
+		Content:  in.Content,
+		URL:      in.URL,
+		Category: in.Category,
+		Tags:     in.Tags,
+	}
+	if err := h.createDocument(c, &doc); err != nil {
+		abort(c, err)
+		return
+	}
+	c.Header("Location", fmt.Sprintf("%s/documents/%d", v2Prefix, doc.ID))
+	c.JSON(http.StatusCreated, doc)
+}
+
+func (h *Handlers) deleteDocumentV2Handler(c *gin.Context) {
+	if err := h.deleteDocument(c); err != nil {
+		abort(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// hits summarises search results, cutting content down to the preview the
+// model is given.
+func (h *Handlers) hits(results []models.SearchResult) []models.SearchHit {
+	hits := make([]models.SearchHit, len(results))
+	for i, r := range results {
+		tags := []string(r.Document.Tags)
+		if tags == nil {
+			tags = []string{}
+		}
+		hits[i] = models.SearchHit{
+			ID:       r.Document.ID,
+			Title:    r.Document.Title,
+			URL:      r.Document.URL,
+			Category: r.Document.Category,
+			Tags:     tags,
+			Snippet:  services.Preview(r.Document.Content, h.cfg.Retrieval.ContextPreviewChars),
+			Score:    r.Score,
+		}
+	}
+	return hits
+}
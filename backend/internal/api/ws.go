@@ -0,0 +1,284 @@
+// backend/internal/api/ws.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+// /api/ws carries chat sessions over a WebSocket, one JSON message per text
+// frame. The client sends models.WSRequest; the server replies with
+// models.WSEvent, tagged with the answer's ID: progress while the answer is
+// being prepared, then the v2 stream events (start, sources, delta, usage and
+// done, or error) with the same payloads. Several answers can be in flight on
+// one connection, up to websocket.max_active_chats.
+
+const (
+	eventProgress = "progress" // models.ChatProgress
+
+	// wsWriteWait bounds each write, so a stalled client cannot hold a
+	// session open.
+	wsWriteWait = 10 * time.Second
+)
+
+// wsToken lets browsers, which cannot set headers on a WebSocket handshake,
+// pass the bearer token as ?access_token=. It is moved to the Authorization
+// header so the access log never sees it.
+func wsToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			if c.GetHeader("Authorization") == "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del("access_token")
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+// allowedOrigin accepts handshakes from the CORS origins, and from clients
+// that are not browsers and so send no Origin.
+func (h *Handlers) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range h.cfg.Server.CORSOrigins {
+		if o = strings.TrimSpace(o); o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// wsSession is one WebSocket connection.
+type wsSession struct {
+	h    *Handlers
+	conn *websocket.Conn
+	sc   requestScope
+	rate *rateLimiter
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	active  map[string]*wsAnswer // answers in flight, by ID
+	answers sync.WaitGroup
+}
+
+type wsAnswer struct {
+	cancel context.CancelFunc
+}
+
+func (h *Handlers) websocketHandler(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		invalid(c, "%s only accepts WebSocket connections", c.Request.URL.Path)
+		return
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: h.allowedOrigin}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // Upgrade has answered the handshake
+	}
+	limits := h.cfg.WebSocket
+	sc := scopeOf(c)
+	sc.endpoint = "ws"
+	s := &wsSession{
+		h:      h,
+		conn:   conn,
+		sc:     sc,
+		rate:   newRateLimiter(limits.MessagesPerMinute, limits.MessagesPerMinute),
+		active: map[string]*wsAnswer{},
+	}
+	// The connection is hijacked, so Shutdown only waits for it because it
+	// is tracked here.
+	h.running.Add(1)
+	defer h.running.Done()
+	s.serve()
+}
+
+// serve reads requests until the connection fails or the server shuts down,
+// then cancels whatever is still generating.
+func (s *wsSession) serve() {
+	ctx, cancel := context.WithCancel(s.sc.ctx)
+	defer func() {
+		cancel()
+		s.answers.Wait()
+		s.conn.Close()
+	}()
+
+	limits := s.h.cfg.WebSocket
+	ping := time.Duration(limits.PingSeconds) * time.Second
+	s.conn.SetReadLimit(int64(limits.MaxMessageBytes))
+	_ = s.conn.SetReadDeadline(time.Now().Add(2 * ping))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(2 * ping))
+	})
+	go s.keepAlive(ctx, ping)
+
+	for {
+		kind, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logging.For("api").DebugContext(s.sc.ctx, "websocket closed", "error", err)
+			}
+			return
+		}
+		if kind != websocket.TextMessage {
+			s.fail("", apierror.New(apierror.CodeInvalidRequest, "Messages must be JSON text frames."))
+			continue
+		}
+		s.handle(ctx, data)
+	}
+}
+
+// keepAlive pings every interval and closes the connection when the server
+// shuts down.
+func (s *wsSession) keepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-s.h.jobs.Done():
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			_ = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+			s.conn.Close()
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *wsSession) handle(ctx context.Context, data []byte) {
+	if ok, wait := s.rate.allow("", time.Now()); !ok {
+		s.fail("", apierror.Newf(apierror.CodeRateLimited,
+			"Too many messages on this connection. Retry in %d seconds.", int(wait.Seconds())+1))
+		return
+	}
+	var req models.WSRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		s.fail("", apierror.Newf(apierror.CodeInvalidRequest, "The message is not valid JSON: %v", err))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		s.fail(req.ID, apierror.Newf(apierror.CodeInvalidRequest, "%v", err))
+		return
+	}
+	switch req.Type {
+	case "chat":
+		if len(req.Messages) == 0 {
+			s.fail(req.ID, apierror.New(apierror.CodeInvalidRequest, "A chat needs at least one message."))
+			return
+		}
+		s.chat(ctx, req)
+	case "cancel":
+		s.cancel(req.ID)
+	}
+}
+
+// chat starts answering req in the background.
+func (s *wsSession) chat(parent context.Context, req models.WSRequest) {
+	id := req.ID
+	if id == "" {
+		id = newRequestID()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	answer := &wsAnswer{cancel: cancel}
+	s.mu.Lock()
+	_, busy := s.active[id]
+	full := len(s.active) >= s.h.cfg.WebSocket.MaxActiveChats
+	if !busy && !full {
+		s.active[id] = answer
+	}
+	s.mu.Unlock()
+	switch {
+	case busy:
+		cancel()
+		s.fail(id, apierror.Newf(apierror.CodeConflict, "Answer %q is already in progress.", id))
+		return
+	case full:
+		cancel()
+		s.fail(id, apierror.Newf(apierror.CodeRateLimited,
+			"This connection already has %d answers in progress; wait for one to finish or cancel it.", s.h.cfg.WebSocket.MaxActiveChats))
+		return
+	}
+
+	s.answers.Add(1)
+	go func() {
+		defer s.answers.Done()
+		defer s.release(id, answer)
+		s.send(models.WSEvent{Type: eventProgress, ID: id, Data: models.ChatProgress{Stage: "retrieving"}})
+		pending, err := s.h.openAnswer(ctx, s.sc, req.Messages)
+		if err != nil {
+			s.fail(id, classify(s.sc.ctx, err))
+			return
+		}
+		s.send(models.WSEvent{Type: eventProgress, ID: id, Data: models.ChatProgress{Stage: "generating"}})
+
+		stream := newChatStream(cancel)
+		go s.h.generate(s.sc, stream, pending)
+		for seq := 0; ; {
+			events, done, changed := stream.since(seq)
+			for _, e := range events {
+				s.send(models.WSEvent{Type: e.Name, ID: id, Data: e.Data})
+				seq = e.Seq
+			}
+			if done {
+				return
+			}
+			<-changed
+		}
+	}()
+}
+
+// cancel stops the answer with id, if it is still going; it ends with an
+// error event whose code is request_cancelled.
+func (s *wsSession) cancel(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.active[id]; ok {
+		a.cancel()
+		delete(s.active, id)
+	}
+}
+
+// release forgets a finished answer, unless a cancel already has and its ID
+// has been reused.
+func (s *wsSession) release(id string, a *wsAnswer) {
+	a.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[id] == a {
+		delete(s.active, id)
+	}
+}
+
+func (s *wsSession) fail(id string, e *apierror.Error) {
+	s.send(models.WSEvent{Type: eventError, ID: id, Data: e.Problem(s.sc.path, logging.RequestID(s.sc.ctx))})
+}
+
+// send writes ev. A failed write means the connection is gone, which the
+// read loop notices, so the error is dropped.
+func (s *wsSession) send(ev models.WSEvent) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	_ = s.conn.WriteJSON(ev)
+}
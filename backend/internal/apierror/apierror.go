@@ -0,0 +1,231 @@
+// backend/internal/apierror/apierror.go
+
+// Package apierror is the API's error model. Every failure a client sees is
+// an *Error with a stable machine-readable Code, rendered as an RFC 7807
+// problem document. The underlying cause is kept for logs and never sent.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Code identifies a kind of failure. Codes are part of the API contract:
+// clients branch on them, so existing ones never change meaning.
+type Code string
+
+const (
+	CodeInvalidRequest      Code = "invalid_request"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeAuthNotConfigured   Code = "auth_not_configured"
+	CodeNotFound            Code = "not_found"
+	CodeModelNotFound       Code = "model_not_found"
+	CodeMethodNotAllowed    Code = "method_not_allowed"
+	CodeConflict            Code = "conflict"
+	CodeRateLimited         Code = "rate_limited"
+	CodeRequestCancelled    Code = "request_cancelled"
+	CodeTimeout             Code = "timeout"
+	CodeInternal            Code = "internal_error"
+	CodeDatabaseError       Code = "database_error"
+	CodeMisconfigured       Code = "misconfigured"
+	CodeContextTooLong      Code = "context_length_exceeded"
+	CodeContentFiltered     Code = "content_filtered"
+	CodeProviderRateLimit   Code = "provider_rate_limited"
+	CodeProviderQuota       Code = "provider_quota_exceeded"
+	CodeProviderAuth        Code = "provider_auth_failed"
+	CodeProviderError       Code = "provider_error"
+	CodeProviderTimeout     Code = "provider_timeout"
+	CodeProviderUnavailable Code = "provider_unavailable"
+)
+
+// StatusClientClosedRequest is reported, for logs and metrics, when the
+// client went away before the response was ready.
+const StatusClientClosedRequest = 499
+
+type kind struct {
+	status int
+	title  string
+}
+
+var kinds = map[Code]kind{
+	CodeInvalidRequest:      {http.StatusBadRequest, "Invalid request"},
+	CodeUnauthorized:        {http.StatusUnauthorized, "Authentication required"},
+	CodeAuthNotConfigured:   {http.StatusServiceUnavailable, "Authentication is not configured"},
+	CodeNotFound:            {http.StatusNotFound, "Not found"},
+	CodeModelNotFound:       {http.StatusNotFound, "Model not available"},
+	CodeMethodNotAllowed:    {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeConflict:            {http.StatusConflict, "Conflict"},
+	CodeRateLimited:         {http.StatusTooManyRequests, "Rate limit exceeded"},
+	CodeRequestCancelled:    {StatusClientClosedRequest, "Request cancelled"},
+	CodeTimeout:             {http.StatusGatewayTimeout, "Request timed out"},
+	CodeInternal:            {http.StatusInternalServerError, "Internal error"},
+	CodeDatabaseError:       {http.StatusServiceUnavailable, "Database unavailable"},
+	CodeMisconfigured:       {http.StatusInternalServerError, "Server misconfigured"},
+	CodeContextTooLong:      {http.StatusRequestEntityTooLarge, "Conversation too long"},
+	CodeContentFiltered:     {http.StatusUnprocessableEntity, "Content rejected by the model provider"},
+	CodeProviderRateLimit:   {http.StatusTooManyRequests, "Model provider rate limit"},
+	CodeProviderQuota:       {http.StatusServiceUnavailable, "Model provider quota exhausted"},
+	CodeProviderAuth:        {http.StatusBadGateway, "Model provider rejected credentials"},
+	CodeProviderError:       {http.StatusBadGateway, "Model provider error"},
+	CodeProviderTimeout:     {http.StatusGatewayTimeout, "Model provider timed out"},
+	CodeProviderUnavailable: {http.StatusBadGateway, "Model provider unavailable"},
+}
+
+// Error is a failure with its client-facing description.
+type Error struct {
+	Code   Code
+	Status int
+	Title  string
+	// Detail is safe to show the client.
+	Detail string
+	// RetryAfterSeconds, when positive, is sent as Retry-After.
+	RetryAfterSeconds int
+	// Err is the cause. It is logged, not sent.
+	Err error
+}
+
+// New returns an error of kind code with a client-facing detail.
+func New(code Code, detail string) *Error {
+	k, ok := kinds[code]
+	if !ok {
+		k = kinds[CodeInternal]
+	}
+	return &Error{Code: code, Status: k.status, Title: k.title, Detail: detail}
+}
+
+// Newf is New with a formatted detail.
+func Newf(code Code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns an error of kind code caused by err.
+func Wrap(code Code, err error, detail string) *Error {
+	e := New(code, detail)
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// From classifies err. An *Error anywhere in the chain is returned as is;
+// cancellation, deadlines and provider errors get their own codes; anything
+// else is an internal error whose cause stays private.
+func From(err error) *Error {
+	var e *Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, context.Canceled):
+		return Wrap(CodeRequestCancelled, err, "The request was cancelled.")
+	}
+	if p := fromProvider(err); p != nil {
+		return p
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(CodeTimeout, err, "The request timed out.")
+	}
+	return Wrap(CodeInternal, err, "An unexpected error occurred.")
+}
+
+// Database classifies a failed store call: cancellation and provider errors
+// keep their codes, anything else is a database error described by detail.
+func Database(err error, detail string) *Error {
+	if e := From(err); e.Code != CodeInternal {
+		return e
+	}
+	return Wrap(CodeDatabaseError, err, detail)
+}
+
+// fromProvider maps OpenAI-compatible API errors, or returns nil.
+func fromProvider(err error) *Error {
+	status := 0
+	code := ""
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+		code, _ = apiErr.Code.(string)
+		if code == "" {
+			code = apiErr.Type
+		}
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	default:
+		// The provider client is the only outbound HTTP the API makes.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && !urlErr.Timeout() {
+			return Wrap(CodeProviderUnavailable, err, "The model provider is unavailable.")
+		}
+		return nil
+	}
+
+	switch {
+	case code == "context_length_exceeded" || code == "string_above_max_length":
+		return Wrap(CodeContextTooLong, err, "The conversation and documentation context exceed the model's context window. Shorten the conversation and try again.")
+	case code == "content_filter" || code == "content_policy_violation" || strings.Contains(code, "content_filter"):
+		return Wrap(CodeContentFiltered, err, "The model provider declined to process this content.")
+	case code == "insufficient_quota":
+		return Wrap(CodeProviderQuota, err, "The model provider quota is exhausted.")
+	case status == http.StatusTooManyRequests:
+		e := Wrap(CodeProviderRateLimit, err, "The model provider is rate limiting requests. Retry shortly.")
+		e.RetryAfterSeconds = 10
+		return e
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return Wrap(CodeProviderAuth, err, "The server's model provider credentials were rejected.")
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return Wrap(CodeProviderTimeout, err, "The model provider did not respond in time.")
+	case status >= 500 || status == 0:
+		return Wrap(CodeProviderUnavailable, err, "The model provider is unavailable.")
+	default:
+		return Wrap(CodeProviderError, err, "The model provider rejected the request.")
+	}
+}
+
+// Problem is an RFC 7807 problem document, extended with the error code and
+// request ID.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ContentType is the media type of problem documents.
+const ContentType = "application/problem+json"
+
+// TypeURI is the problem type of code.
+func TypeURI(code Code) string {
+	return "/problems/" + string(code)
+}
+
+// Problem describes e for the client. instance is the request path.
+func (e *Error) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:      TypeURI(e.Code),
+		Title:     e.Title,
+		Status:    e.Status,
+		Detail:    e.Detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+	}
+}
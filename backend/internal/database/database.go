@@ -3,21 +3,25 @@ package database
 
 import (
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// Database wraps the GORM connection. The schema itself is owned by the SQL
+// files in migrations/ and applied with cmd/migrate; the server never
+// auto-migrates.
 type Database struct {
 	*gorm.DB
 }
 
 func New(dsn string) (*Database, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logging.GORM(),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -29,15 +33,11 @@ func New(dsn string) (*Database, error) {
 	// Enable pgvector extension
 	db.Exec("CREATE EXTENSION IF NOT EXISTS vector")
 
-	log.Println("Database connected successfully")
+	logging.For("app").Info("database connected")
 
 	return &Database{db}, nil
 }
 
-func (db *Database) AutoMigrate(models ...interface{}) error {
-	return db.DB.AutoMigrate(models...)
-}
-
 func (db *Database) HealthCheck() error {
 	sqlDB, err := db.DB.DB()
 	if err != nil {
@@ -45,3 +45,19 @@ func (db *Database) HealthCheck() error {
 	}
 	return sqlDB.Ping()
 }
+
+// CountMissingEmbeddings returns how many documents have no vector in the
+// given embedding space. Those documents are invisible to search until they
+// are re-embedded.
+func (db *Database) CountMissingEmbeddings(space models.EmbeddingSpace) (int64, error) {
+	var missing int64
+	err := db.Raw(`
+		SELECT COUNT(*)
+		FROM documents d
+		WHERE NOT EXISTS (
+			SELECT 1 FROM embeddings e
+			WHERE e.document_id = d.id AND e.model = ? AND e.dimensions = ?
+		)
+	`, space.Model, space.Dimensions).Scan(&missing).Error
+	return missing, err
+}
@@ -0,0 +1,201 @@
+// backend/internal/database/vectorindex.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	IndexHNSW    = "hnsw"
+	IndexIVFFlat = "ivfflat"
+)
+
+// MaxIndexedDimensions is pgvector's limit for indexing the vector type.
+// Larger spaces can be searched, but only by a sequential scan.
+const MaxIndexedDimensions = 2000
+
+// VectorIndexSpec describes an approximate nearest neighbour index over the
+// vectors of one embedding space. Because the embeddings column holds vectors
+// of several sizes, each index is a partial expression index that casts to
+// the space's fixed size; queries must use VectorExpr/SpacePredicate so the
+// planner can match them.
+type VectorIndexSpec struct {
+	Space  models.EmbeddingSpace
+	Method string
+	// HNSW build parameters (pgvector defaults: m=16, ef_construction=64).
+	M              int
+	EfConstruction int
+	// IVFFlat list count; 0 picks rows/1000 (minimum 10) at build time.
+	Lists int
+}
+
+// Validate checks the spec against pgvector's limits.
+func (s VectorIndexSpec) Validate() error {
+	if s.Space.Dimensions < 1 || s.Space.Dimensions > MaxIndexedDimensions {
+		return fmt.Errorf("pgvector can only index up to %d dimensions (%s has %d)", MaxIndexedDimensions, s.Space.Model, s.Space.Dimensions)
+	}
+	switch s.Method {
+	case IndexHNSW:
+		if s.M < 2 || s.M > 100 {
+			return fmt.Errorf("hnsw m must be between 2 and 100")
+		}
+		if s.EfConstruction < 2*s.M || s.EfConstruction > 1000 {
+			return fmt.Errorf("hnsw ef_construction must be between 2*m and 1000")
+		}
+	case IndexIVFFlat:
+		if s.Lists < 0 || s.Lists > 32768 {
+			return fmt.Errorf("ivfflat lists must be between 1 and 32768")
+		}
+	default:
+		return fmt.Errorf("unknown index method %q (want %s or %s)", s.Method, IndexHNSW, IndexIVFFlat)
+	}
+	return nil
+}
+
+var (
+	nonIdent        = regexp.MustCompile(`[^a-z0-9]+`)
+	vectorIndexName = regexp.MustCompile(`^idx_embeddings_(hnsw|ivfflat)_[a-z0-9_]+$`)
+)
+
+// Name is the index name for this method and space, e.g.
+// idx_embeddings_hnsw_text_embedding_ada_002_1536.
+func (s VectorIndexSpec) Name() string {
+	slug := strings.Trim(nonIdent.ReplaceAllString(strings.ToLower(s.Space.Model), "_"), "_")
+	name := fmt.Sprintf("idx_embeddings_%s_%s_%d", s.Method, slug, s.Space.Dimensions)
+	if len(name) > 63 { // Postgres identifier limit
+		name = name[:63]
+	}
+	return name
+}
+
+// VectorExpr is the expression to order by, matching the indexed expression.
+func VectorExpr(alias string, space models.EmbeddingSpace) string {
+	return fmt.Sprintf("(%s.vector::vector(%d))", alias, space.Dimensions)
+}
+
+// SpacePredicate restricts rows to one embedding space. The values are
+// inlined rather than bound so the planner can prove the predicate implies a
+// partial index's WHERE clause.
+func SpacePredicate(alias string, space models.EmbeddingSpace) string {
+	return fmt.Sprintf("%s.model = '%s' AND %s.dimensions = %d",
+		alias, strings.ReplaceAll(space.Model, "'", "''"), alias, space.Dimensions)
+}
+
+// createSQL renders CREATE INDEX for the spec under the given name.
+func (s VectorIndexSpec) createSQL(name string, lists int) string {
+	with := ""
+	switch s.Method {
+	case IndexHNSW:
+		with = fmt.Sprintf("WITH (m = %d, ef_construction = %d)", s.M, s.EfConstruction)
+	case IndexIVFFlat:
+		with = fmt.Sprintf("WITH (lists = %d)", lists)
+	}
+	return fmt.Sprintf(`CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON embeddings USING %s (%s vector_cosine_ops) %s WHERE %s`,
+		name, s.Method, VectorExpr("embeddings", s.Space), with, SpacePredicate("embeddings", s.Space))
+}
+
+func (s VectorIndexSpec) lists(ctx context.Context, db *gorm.DB) (int, error) {
+	if s.Method != IndexIVFFlat || s.Lists > 0 {
+		return s.Lists, nil
+	}
+	var rows int64
+	err := db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM embeddings e WHERE ` + SpacePredicate("e", s.Space)).Scan(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+	lists := int(rows / 1000)
+	if lists < 10 {
+		lists = 10
+	}
+	return lists, nil
+}
+
+// CreateVectorIndex builds the index without blocking writes. It is a no-op
+// if an index with the same name already exists.
+func CreateVectorIndex(ctx context.Context, db *gorm.DB, spec VectorIndexSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	lists, err := spec.lists(ctx, db)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Exec(spec.createSQL(spec.Name(), lists)).Error
+}
+
+// RebuildVectorIndex rebuilds the index with the spec's (possibly new)
+// parameters. The replacement is built concurrently under a temporary name
+// and swapped in, so searches keep an index the whole time.
+func RebuildVectorIndex(ctx context.Context, db *gorm.DB, spec VectorIndexSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	lists, err := spec.lists(ctx, db)
+	if err != nil {
+		return err
+	}
+	name := spec.Name()
+	tmp := name
+	if len(tmp) > 59 {
+		tmp = tmp[:59]
+	}
+	tmp += "_new"
+
+	db = db.WithContext(ctx)
+	// A previous failed rebuild can leave an invalid temporary index behind.
+	if err := db.Exec(`DROP INDEX CONCURRENTLY IF EXISTS ` + tmp).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(spec.createSQL(tmp, lists)).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`DROP INDEX CONCURRENTLY IF EXISTS ` + name).Error; err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf(`ALTER INDEX %s RENAME TO %s`, tmp, name)).Error
+}
+
+// DropVectorIndex removes an ANN index by name.
+func DropVectorIndex(ctx context.Context, db *gorm.DB, name string) error {
+	if !vectorIndexName.MatchString(name) {
+		return fmt.Errorf("%q is not a vector index name", name)
+	}
+	return db.WithContext(ctx).Exec(`DROP INDEX CONCURRENTLY IF EXISTS ` + name).Error
+}
+
+// VectorIndexInfo describes an existing ANN index on embeddings.
+type VectorIndexInfo struct {
+	Name       string `json:"name"`
+	Method     string `json:"method"`
+	Definition string `json:"definition"`
+	SizeBytes  int64  `json:"size_bytes"`
+	Valid      bool   `json:"valid"`
+}
+
+// ListVectorIndexes returns the HNSW and IVFFlat indexes on embeddings.
+// Invalid indexes are left behind by interrupted concurrent builds and
+// should be rebuilt or dropped.
+func ListVectorIndexes(ctx context.Context, db *gorm.DB) ([]VectorIndexInfo, error) {
+	var out []VectorIndexInfo
+	err := db.WithContext(ctx).Raw(`
+		SELECT
+			c.relname AS name,
+			am.amname AS method,
+			pg_get_indexdef(c.oid) AS definition,
+			pg_relation_size(c.oid) AS size_bytes,
+			i.indisvalid AS valid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE t.relname = 'embeddings' AND am.amname IN ('hnsw', 'ivfflat')
+		ORDER BY c.relname
+	`).Scan(&out).Error
+	return out, err
+}
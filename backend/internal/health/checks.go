@@ -0,0 +1,87 @@
+// backend/internal/health/checks.go
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/migrate"
+)
+
+// Defaults for the standard checks. Database checks are cheap and run
+// often; provider checks cost an API round trip and are cached longer.
+const (
+	databaseTimeout = 2 * time.Second
+	databaseTTL     = 5 * time.Second
+	providerTimeout = 5 * time.Second
+	providerTTL     = time.Minute
+)
+
+// Database pings the connection pool.
+func Database(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Timeout:  databaseTimeout,
+		TTL:      databaseTTL,
+		Func:     db.PingContext,
+	}
+}
+
+// PGVector checks the vector extension is installed.
+func PGVector(db *sql.DB) Check {
+	return Check{
+		Name:     "pgvector",
+		Critical: true,
+		Timeout:  databaseTimeout,
+		TTL:      databaseTTL,
+		Func: func(ctx context.Context) error {
+			var version string
+			err := db.QueryRowContext(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'vector'`).Scan(&version)
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New("extension vector is not installed")
+			}
+			return err
+		},
+	}
+}
+
+// Migrations checks the schema is at the version this build expects. A
+// database that is behind would fail queries against new columns; one that
+// is ahead means an older build is running against a newer schema.
+func Migrations(db *sql.DB, latest int64) Check {
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Timeout:  databaseTimeout,
+		TTL:      databaseTTL,
+		Func: func(ctx context.Context) error {
+			current, err := migrate.CurrentVersion(ctx, db)
+			if err != nil {
+				return err
+			}
+			if current != latest {
+				return fmt.Errorf("schema is at version %d, this build expects %d; run cmd/migrate", current, latest)
+			}
+			return nil
+		},
+	}
+}
+
+// OpenAIModel checks the provider is reachable, accepts the API key and
+// serves model. It fetches the model's metadata, which costs no tokens.
+func OpenAIModel(name string, client *openai.Client, model string) Check {
+	return Check{
+		Name:    name,
+		Timeout: providerTimeout,
+		TTL:     providerTTL,
+		Func: func(ctx context.Context) error {
+			_, err := client.GetModel(ctx, model)
+			return err
+		},
+	}
+}
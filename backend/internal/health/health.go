@@ -0,0 +1,129 @@
+// backend/internal/health/health.go
+
+// Package health runs readiness checks against the server's dependencies.
+// Results are cached per check so a load balancer polling /readyz does not
+// turn into a stream of database pings and provider API calls.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// Check is one dependency probe.
+type Check struct {
+	Name string
+	// Critical checks make the server unready when they fail; the others
+	// only mark it degraded.
+	Critical bool
+	// Timeout bounds one run of Func.
+	Timeout time.Duration
+	// TTL is how long a result is reused before Func runs again.
+	TTL  time.Duration
+	Func func(ctx context.Context) error
+}
+
+// ComponentStatus is the latest result of one check.
+type ComponentStatus struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the overall readiness and the status of every component.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Ready reports whether every critical check passed.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Monitor runs a fixed set of checks.
+type Monitor struct {
+	// Now is the clock used for caching; it defaults to time.Now.
+	Now func() time.Time
+
+	checks []*cachedCheck
+}
+
+type cachedCheck struct {
+	Check
+	mu   sync.Mutex // held while running, so concurrent callers share a run
+	last ComponentStatus
+}
+
+func NewMonitor(checks ...Check) *Monitor {
+	m := &Monitor{Now: time.Now}
+	for _, c := range checks {
+		m.checks = append(m.checks, &cachedCheck{Check: c})
+	}
+	return m
+}
+
+// Run returns the current report, running in parallel every check whose
+// cached result has expired.
+func (m *Monitor) Run(ctx context.Context) Report {
+	statuses := make([]ComponentStatus, len(m.checks))
+	var wg sync.WaitGroup
+	for i, c := range m.checks {
+		wg.Add(1)
+		go func(i int, c *cachedCheck) {
+			defer wg.Done()
+			statuses[i] = m.status(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(m.checks))}
+	for i, c := range m.checks {
+		s := statuses[i]
+		report.Components[c.Name] = s
+		switch {
+		case s.Status == StatusOK:
+		case c.Critical:
+			report.Status = StatusFail
+		case report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (m *Monitor) status(ctx context.Context, c *cachedCheck) ComponentStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := m.Now()
+	if !c.last.CheckedAt.IsZero() && now.Sub(c.last.CheckedAt) < c.TTL {
+		return c.last
+	}
+
+	// A caller hanging up must not leave a failure cached for everyone else.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.Func(ctx)
+	s := ComponentStatus{
+		Status:    StatusOK,
+		Critical:  c.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+		CheckedAt: now,
+	}
+	if err != nil {
+		s.Status = StatusFail
+		s.Error = err.Error()
+	}
+	c.last = s
+	return s
+}
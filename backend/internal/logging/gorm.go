@@ -0,0 +1,72 @@
+// backend/internal/logging/gorm.go
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// SlowQuery is the duration above which a statement is logged at warn.
+const SlowQuery = 200 * time.Millisecond
+
+// GORM returns a GORM logger writing to component "db": failed statements
+// at error, slow ones at warn and every statement at debug. Its level comes
+// from the logging configuration, so LogMode is ignored.
+func GORM() gormlogger.Interface {
+	return gormLogger{}
+}
+
+type gormLogger struct{}
+
+func (l gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface { return l }
+
+func (gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	For("db").InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	For("db").WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	For("db").ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	log := For("db")
+	elapsed := time.Since(begin)
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "query failed"
+	case elapsed > SlowQuery:
+		level, msg = slog.LevelWarn, "slow query"
+	default:
+		level, msg = slog.LevelDebug, "query"
+	}
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs := []any{"sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds()}
+	if level == slog.LevelError {
+		attrs = append(attrs, "error", err)
+	}
+	log.Log(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter keeps bound values, which include prompts and answers, out
+// of the logged SQL when redacting.
+func (gormLogger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if Redacting() {
+		return sql, nil
+	}
+	return sql, params
+}
@@ -0,0 +1,150 @@
+// backend/internal/logging/logging.go
+
+// Package logging is the server's structured log. Setup installs a JSON or
+// text slog handler; For returns the logger of one component, filtered at
+// that component's level. Records logged with a request context carry its
+// request and trace IDs.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted replaces sensitive values when redaction is on.
+const Redacted = "[REDACTED]"
+
+// Attribute keys that hold user text. They are dropped from records when
+// redaction is on, wherever they appear.
+const (
+	KeyPrompt   = "prompt"
+	KeyQuery    = "query"
+	KeyResponse = "response"
+)
+
+var sensitiveKeys = map[string]bool{KeyPrompt: true, KeyQuery: true, KeyResponse: true}
+
+var email = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+type state struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+	redact  bool
+}
+
+var current atomic.Pointer[state]
+
+func init() {
+	current.Store(&state{
+		handler: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		level:   slog.LevelInfo,
+		levels:  map[string]slog.Level{"db": slog.LevelWarn},
+	})
+}
+
+// Setup makes cfg the logging configuration and routes the standard
+// library's default logger (and log.Printf) through it as component "app".
+// cfg is assumed valid.
+func Setup(cfg config.LoggingConfig, w io.Writer) {
+	s := &state{levels: map[string]slog.Level{}, redact: cfg.Redact}
+	_ = s.level.UnmarshalText([]byte(cfg.Level))
+	for component, level := range cfg.Levels {
+		var l slog.Level
+		_ = l.UnmarshalText([]byte(level))
+		s.levels[component] = l
+	}
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if cfg.Redact {
+		opts.ReplaceAttr = redactAttr
+	}
+	if cfg.Format == "text" {
+		s.handler = slog.NewTextHandler(w, opts)
+	} else {
+		s.handler = slog.NewJSONHandler(w, opts)
+	}
+	current.Store(s)
+	slog.SetDefault(For("app"))
+}
+
+// For returns the logger of component. Take it when needed rather than
+// caching it in a package variable, so it follows Setup.
+func For(component string) *slog.Logger {
+	s := current.Load()
+	level, ok := s.levels[component]
+	if !ok {
+		level = s.level
+	}
+	return slog.New(&handler{
+		next:  s.handler.WithAttrs([]slog.Attr{slog.String("component", component)}),
+		level: level,
+	})
+}
+
+// Redacting reports whether user text must be kept out of logs.
+func Redacting() bool {
+	return current.Load().redact
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[a.Key] {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindString {
+		if v := a.Value.String(); email.MatchString(v) {
+			return slog.String(a.Key, email.ReplaceAllString(v, Redacted))
+		}
+	}
+	return a
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// handler applies the component level and adds the request and trace IDs
+// found in the record's context.
+type handler struct {
+	next  slog.Handler
+	level slog.Level
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs), level: h.level}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,121 @@
+// backend/internal/metrics/instrument.go
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+)
+
+// Operation labels for provider metrics.
+const (
+	OpEmbedding  = "embedding"
+	OpChat       = "chat"
+	OpChatStream = "chat_stream"
+)
+
+func (m *Metrics) observeCall(model, op string, start time.Time, err error) {
+	m.ProviderDuration.WithLabelValues(Provider, model, op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.ProviderErrors.WithLabelValues(Provider, model, op).Inc()
+	}
+}
+
+// Embedder records latency, errors and tokens of every Embed call.
+func (m *Metrics) Embedder(next services.Embedder) services.Embedder {
+	return &embedder{m: m, next: next}
+}
+
+type embedder struct {
+	m    *Metrics
+	next services.Embedder
+}
+
+func (e *embedder) Embed(ctx context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	start := time.Now()
+	vectors, tokens, err := e.next.Embed(ctx, space, texts)
+	e.m.observeCall(space.Model, OpEmbedding, start, err)
+	e.m.ObserveTokens(space.Model, TokensEmbedding, tokens)
+	return vectors, tokens, err
+}
+
+// ChatProvider records latency, errors and tokens of completions. Streamed
+// tokens are counted from the final usage chunk.
+func (m *Metrics) ChatProvider(next services.ChatProvider) services.ChatProvider {
+	return &chatProvider{m: m, next: next}
+}
+
+type chatProvider struct {
+	m    *Metrics
+	next services.ChatProvider
+}
+
+func (p *chatProvider) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	start := time.Now()
+	resp, err := p.next.CreateChatCompletion(ctx, req)
+	p.m.observeCall(req.Model, OpChat, start, err)
+	if err == nil {
+		p.m.ObserveTokens(req.Model, TokensPrompt, resp.Usage.PromptTokens)
+		p.m.ObserveTokens(req.Model, TokensCompletion, resp.Usage.CompletionTokens)
+	}
+	return resp, err
+}
+
+func (p *chatProvider) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (services.ChatStream, error) {
+	start := time.Now()
+	stream, err := p.next.CreateChatCompletionStream(ctx, req)
+	p.m.observeCall(req.Model, OpChatStream, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &chatStream{m: p.m, model: req.Model, next: stream}, nil
+}
+
+type chatStream struct {
+	m     *Metrics
+	model string
+	next  services.ChatStream
+}
+
+func (s *chatStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	resp, err := s.next.Recv()
+	if err == nil && resp.Usage != nil {
+		s.m.ObserveTokens(s.model, TokensPrompt, resp.Usage.PromptTokens)
+		s.m.ObserveTokens(s.model, TokensCompletion, resp.Usage.CompletionTokens)
+	}
+	return resp, err
+}
+
+func (s *chatStream) Close() error {
+	return s.next.Close()
+}
+
+// searcher is the retrieval interface the API depends on.
+type searcher interface {
+	Search(ctx context.Context, q services.SearchQuery) (services.Retrieval, error)
+}
+
+// Searcher records how many documents each successful retrieval returned
+// and how close they were.
+func (m *Metrics) Searcher(next searcher) searcher {
+	return &instrumentedSearcher{m: m, next: next}
+}
+
+type instrumentedSearcher struct {
+	m    *Metrics
+	next searcher
+}
+
+func (s *instrumentedSearcher) Search(ctx context.Context, q services.SearchQuery) (services.Retrieval, error) {
+	retrieval, err := s.next.Search(ctx, q)
+	if err == nil {
+		s.m.RetrievalResults.Observe(float64(len(retrieval.Results)))
+		for _, r := range retrieval.Results {
+			s.m.RetrievalScore.Observe(r.Score)
+		}
+	}
+	return retrieval, err
+}
@@ -0,0 +1,50 @@
+// backend/internal/metrics/jobs.go
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// jobStatuses are the unfinished states reported as queue depth.
+var jobStatuses = []string{models.ReindexPending, models.ReindexRunning, models.ReindexPaused}
+
+// jobCollector counts unfinished re-index jobs when scraped. A scrape that
+// cannot reach the store reports nothing rather than stale numbers.
+type jobCollector struct {
+	jobs store.ReindexStore
+	desc *prometheus.Desc
+}
+
+func newJobCollector(jobs store.ReindexStore) *jobCollector {
+	return &jobCollector{
+		jobs: jobs,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "reindex_jobs"),
+			"Re-index jobs waiting, running or paused.",
+			[]string{"status"}, nil,
+		),
+	}
+}
+
+func (c *jobCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *jobCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	counts, err := c.jobs.CountReindexJobs(ctx, jobStatuses)
+	if err != nil {
+		logging.For("metrics").Warn("counting reindex jobs failed", "error", err)
+		return
+	}
+	for _, status := range jobStatuses {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[status]), status)
+	}
+}
@@ -0,0 +1,121 @@
+// backend/internal/metrics/metrics.go
+
+// Package metrics defines the Prometheus metrics the server exports on
+// /metrics. Each Metrics has its own registry, so several servers in one
+// process (as in tests) do not collide.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+const namespace = "docs_assistant"
+
+// Provider is the provider label for calls made through the OpenAI client.
+const Provider = "openai"
+
+// Token types for TokensTotal.
+const (
+	TokensPrompt     = "prompt"
+	TokensCompletion = "completion"
+	TokensEmbedding  = "embedding"
+)
+
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	// HTTPDuration is labelled by method, route template and status code.
+	HTTPDuration *prometheus.HistogramVec
+	// ProviderDuration and ProviderErrors are labelled by provider, model and
+	// operation (embedding, chat, chat_stream).
+	ProviderDuration *prometheus.HistogramVec
+	ProviderErrors   *prometheus.CounterVec
+	// TokensTotal is labelled by model and token type.
+	TokensTotal *prometheus.CounterVec
+	// RetrievalResults counts documents returned per search; RetrievalScore
+	// is the cosine distance of each one.
+	RetrievalResults prometheus.Histogram
+	RetrievalScore   prometheus.Histogram
+}
+
+// New creates the metrics, plus the Go runtime and process collectors, on a
+// fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		ProviderDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "provider_request_duration_seconds",
+			Help:      "Latency of embedding and completion calls. Streams are timed to the first response.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"provider", "model", "operation"}),
+		ProviderErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "provider_errors_total",
+			Help:      "Failed embedding and completion calls.",
+		}, []string{"provider", "model", "operation"}),
+		TokensTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_total",
+			Help:      "Tokens billed by the provider.",
+		}, []string{"model", "type"}),
+		RetrievalResults: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "retrieval_results",
+			Help:      "Documents returned per retrieval.",
+			Buckets:   []float64{0, 1, 2, 3, 5, 10, 20, 50},
+		}),
+		RetrievalScore: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "retrieval_score",
+			Help:      "Cosine distance of retrieved documents to the query (lower is closer).",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPDuration,
+		m.ProviderDuration,
+		m.ProviderErrors,
+		m.TokensTotal,
+		m.RetrievalResults,
+		m.RetrievalScore,
+	)
+	return m
+}
+
+// WatchDB exports the connection pool statistics of db (go_sql_* metrics).
+func (m *Metrics) WatchDB(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// WatchReindexJobs exports the number of queued and running re-index jobs,
+// read from jobs at scrape time.
+func (m *Metrics) WatchReindexJobs(jobs store.ReindexStore) {
+	m.Registry.MustRegister(newJobCollector(jobs))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// ObserveTokens adds n tokens of the given type for model.
+func (m *Metrics) ObserveTokens(model, tokenType string, n int) {
+	if n > 0 {
+		m.TokensTotal.WithLabelValues(model, tokenType).Add(float64(n))
+	}
+}
@@ -0,0 +1,258 @@
+// backend/internal/migrate/check.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// Column describes one column, either as the models expect it or as it
+// exists in the database.
+type Column struct {
+	Name string
+	// Type is a canonical Postgres type ("character varying(255)", "vector(1536)").
+	// For model columns without an explicit gorm type it is empty and Family
+	// is used instead.
+	Type    string
+	Family  string
+	NotNull bool
+}
+
+// Table is a named set of columns.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Drift is a single difference between the models and the live schema.
+type Drift struct {
+	Table   string
+	Column  string
+	Problem string
+}
+
+func (d Drift) String() string {
+	if d.Column == "" {
+		return fmt.Sprintf("%s: %s", d.Table, d.Problem)
+	}
+	return fmt.Sprintf("%s.%s: %s", d.Table, d.Column, d.Problem)
+}
+
+// ExpectedSchema derives tables from GORM models using the same naming rules
+// GORM uses at runtime.
+func ExpectedSchema(models ...interface{}) ([]Table, error) {
+	cache := &sync.Map{}
+	var out []Table
+	for _, m := range models {
+		s, err := schema.Parse(m, cache, schema.NamingStrategy{})
+		if err != nil {
+			return nil, fmt.Errorf("parse model %T: %w", m, err)
+		}
+		t := Table{Name: s.Table}
+		for _, f := range s.Fields {
+			if f.DBName == "" {
+				continue
+			}
+			col := Column{Name: f.DBName, NotNull: f.NotNull || f.PrimaryKey}
+			if tagType := f.TagSettings["TYPE"]; tagType != "" {
+				col.Type = CanonicalType(tagType)
+			} else {
+				col.Family = familyOf(f.DataType)
+			}
+			t.Columns = append(t.Columns, col)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// LiveSchema reads the named tables from the current schema.
+func LiveSchema(ctx context.Context, db *sql.DB, tables []string) ([]Table, error) {
+	var out []Table
+	for _, name := range tables {
+		rows, err := db.QueryContext(ctx, `
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relname = $1
+			  AND n.nspname = current_schema()
+			  AND a.attnum > 0
+			  AND NOT a.attisdropped
+			ORDER BY a.attnum`, name)
+		if err != nil {
+			return nil, fmt.Errorf("read columns of %s: %w", name, err)
+		}
+		t := Table{Name: name}
+		for rows.Next() {
+			var col Column
+			if err := rows.Scan(&col.Name, &col.Type, &col.NotNull); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			col.Type = CanonicalType(col.Type)
+			t.Columns = append(t.Columns, col)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(t.Columns) > 0 {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Check compares the live database with models and returns every drift found.
+func Check(ctx context.Context, db *sql.DB, models ...interface{}) ([]Drift, error) {
+	expected, err := ExpectedSchema(models...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(expected))
+	for i, t := range expected {
+		names[i] = t.Name
+	}
+	live, err := LiveSchema(ctx, db, names)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(expected, live), nil
+}
+
+// Diff reports missing tables and columns, type and nullability mismatches,
+// and columns that exist in the database but not in the models.
+func Diff(expected, live []Table) []Drift {
+	liveByName := map[string]Table{}
+	for _, t := range live {
+		liveByName[t.Name] = t
+	}
+
+	var drifts []Drift
+	for _, exp := range expected {
+		lt, ok := liveByName[exp.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Table: exp.Name, Problem: "table missing from database"})
+			continue
+		}
+		liveCols := map[string]Column{}
+		for _, c := range lt.Columns {
+			liveCols[c.Name] = c
+		}
+		seen := map[string]bool{}
+		for _, ec := range exp.Columns {
+			seen[ec.Name] = true
+			lc, ok := liveCols[ec.Name]
+			if !ok {
+				drifts = append(drifts, Drift{Table: exp.Name, Column: ec.Name, Problem: "column missing from database"})
+				continue
+			}
+			if ec.Type != "" && ec.Type != lc.Type {
+				drifts = append(drifts, Drift{Table: exp.Name, Column: ec.Name,
+					Problem: fmt.Sprintf("model type %s, database type %s", ec.Type, lc.Type)})
+			} else if ec.Type == "" && ec.Family != "" && familyOfType(lc.Type) != ec.Family {
+				drifts = append(drifts, Drift{Table: exp.Name, Column: ec.Name,
+					Problem: fmt.Sprintf("model expects a %s column, database type %s", ec.Family, lc.Type)})
+			}
+			if ec.NotNull && !lc.NotNull {
+				drifts = append(drifts, Drift{Table: exp.Name, Column: ec.Name, Problem: "model is NOT NULL, database column is nullable"})
+			} else if !ec.NotNull && lc.NotNull {
+				drifts = append(drifts, Drift{Table: exp.Name, Column: ec.Name, Problem: "database column is NOT NULL, model allows null"})
+			}
+		}
+		var extra []string
+		for name := range liveCols {
+			if !seen[name] {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		for _, name := range extra {
+			drifts = append(drifts, Drift{Table: exp.Name, Column: name, Problem: "column exists in database but not in model"})
+		}
+	}
+	return drifts
+}
+
+var typeAliases = map[string]string{
+	"varchar":     "character varying",
+	"char":        "character",
+	"int":         "integer",
+	"int4":        "integer",
+	"serial":      "integer",
+	"int8":        "bigint",
+	"bigserial":   "bigint",
+	"int2":        "smallint",
+	"float8":      "double precision",
+	"float4":      "real",
+	"bool":        "boolean",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"decimal":     "numeric",
+}
+
+var typeWithArgs = regexp.MustCompile(`^([a-z0-9_ ]+?)\s*(\(.*\))?(\[\])?$`)
+
+// CanonicalType normalizes a type as written in a gorm tag or migration to
+// the spelling format_type() returns, e.g. "VARCHAR(255)" to
+// "character varying(255)" and "timestamp" to "timestamp without time zone".
+func CanonicalType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	m := typeWithArgs.FindStringSubmatch(t)
+	if m == nil {
+		return t
+	}
+	base, args, array := strings.TrimSpace(m[1]), strings.ReplaceAll(m[2], " ", ""), m[3]
+	if alias, ok := typeAliases[base]; ok {
+		base = alias
+	}
+	return base + args + array
+}
+
+// familyOf groups GORM's inferred data types so that, for example, a uint
+// field matches an integer or bigint column.
+func familyOf(dt schema.DataType) string {
+	switch dt {
+	case schema.Bool:
+		return "boolean"
+	case schema.Int, schema.Uint:
+		return "integer"
+	case schema.Float:
+		return "float"
+	case schema.String:
+		return "text"
+	case schema.Time:
+		return "timestamp"
+	case schema.Bytes:
+		return "bytes"
+	default:
+		return ""
+	}
+}
+
+func familyOfType(t string) string {
+	switch {
+	case t == "boolean":
+		return "boolean"
+	case t == "integer" || t == "bigint" || t == "smallint":
+		return "integer"
+	case t == "real" || t == "double precision" || strings.HasPrefix(t, "numeric"):
+		return "float"
+	case t == "text" || strings.HasPrefix(t, "character varying") || strings.HasPrefix(t, "character("):
+		return "text"
+	case strings.HasPrefix(t, "timestamp"):
+		return "timestamp"
+	case t == "bytea":
+		return "bytes"
+	default:
+		return t
+	}
+}
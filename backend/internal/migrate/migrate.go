@@ -0,0 +1,338 @@
+// backend/internal/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered schema change with its forward and reverse SQL.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// AppliedMigration is a row of the schema_migrations table.
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// MigrationStatus pairs a known migration with when (if ever) it was applied.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads NNN_name.up.sql / NNN_name.down.sql pairs from fsys and returns
+// them ordered by version. Every migration needs an up file; the down file is
+// optional, but a migration without one cannot be rolled back.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: bad version: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// noTransaction marks a script that must run outside a transaction, such as
+// one using CREATE INDEX CONCURRENTLY. It must be the script's first line.
+const noTransaction = "-- migrate:no-transaction"
+
+// NoTransaction reports whether script opts out of running in a transaction.
+func NoTransaction(script string) bool {
+	line, _, _ := strings.Cut(strings.TrimLeft(script, " \t\r\n"), "\n")
+	return strings.TrimSpace(line) == noTransaction
+}
+
+// advisoryLockID keeps two migrate processes from racing each other.
+const advisoryLockID = 7_268_400_001
+
+// Runner applies migrations to a Postgres database and records each one in
+// schema_migrations. Every migration runs in its own transaction together
+// with its bookkeeping row, so a failure leaves no partial state behind.
+// Scripts starting with "-- migrate:no-transaction" are the exception: their
+// statements run one by one and the version is recorded afterwards, so they
+// must be safe to re-run (IF NOT EXISTS / IF EXISTS).
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+	// Logf, when set, is called for each migration applied or rolled back.
+	Logf func(format string, args ...interface{})
+}
+
+func NewRunner(db *sql.DB, migrations []Migration) *Runner {
+	return &Runner{db: db, migrations: migrations}
+}
+
+// Latest returns the highest known migration version, or 0 if there are none.
+func (r *Runner) Latest() int64 {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.Goto(ctx, r.Latest())
+}
+
+// Down rolls back the most recent steps migrations.
+func (r *Runner) Down(ctx context.Context, steps int) error {
+	return r.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := r.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := r.apply(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// Goto migrates up or down until exactly the migrations <= version are
+// applied. Goto(0) rolls everything back.
+func (r *Runner) Goto(ctx context.Context, version int64) error {
+	if version != 0 && r.find(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return r.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		// Roll back newer migrations first, newest to oldest...
+		for i := len(r.migrations) - 1; i >= 0; i-- {
+			mig := r.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := r.apply(ctx, conn, mig, false); err != nil {
+					return err
+				}
+			}
+		}
+		// ...then fill in anything missing up to the target, oldest first.
+		for _, mig := range r.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := r.apply(ctx, conn, mig, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and whether it has been applied.
+// Versions recorded in the database but missing from the binary are
+// returned separately so callers can flag them.
+func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, []AppliedMigration, error) {
+	var (
+		statuses []MigrationStatus
+		unknown  []AppliedMigration
+	)
+	err := r.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range r.migrations {
+			s := MigrationStatus{Migration: mig}
+			if a, ok := applied[mig.Version]; ok {
+				s.Applied = true
+				s.AppliedAt = a.AppliedAt
+				delete(applied, mig.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for _, a := range applied {
+			unknown = append(unknown, a)
+		}
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+		return nil
+	})
+	return statuses, unknown, err
+}
+
+// CurrentVersion returns the highest applied version, or 0 on a fresh database.
+func CurrentVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var v sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&v)
+	if err != nil {
+		return 0, err
+	}
+	return v.Int64, nil
+}
+
+func (r *Runner) find(version int64) int {
+	for i, mig := range r.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// withConn pins one connection for the whole run: the advisory lock is
+// session-scoped, so every statement must go through the session holding it.
+func (r *Runner) withConn(ctx context.Context, fn func(*sql.Conn) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]AppliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[int64]AppliedMigration{}
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		out[a.Version] = a
+	}
+	return out, rows.Err()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *Runner) apply(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	script, direction := mig.Up, "up"
+	if !up {
+		if mig.Down == "" {
+			return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		script, direction = mig.Down, "down"
+	}
+
+	run := func(ex execer) error {
+		for _, stmt := range SplitStatements(script) {
+			if _, err := ex.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %d_%s (%s) failed: %w\n---\n%s\n---", mig.Version, mig.Name, direction, err, stmt)
+			}
+		}
+		return nil
+	}
+
+	outside := NoTransaction(script)
+	if outside {
+		if err := run(conn); err != nil {
+			return err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if !outside {
+		if err := run(tx); err != nil {
+			return err
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("record migration %d: %w", mig.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if r.Logf != nil {
+		r.Logf("%s %03d_%s", direction, mig.Version, mig.Name)
+	}
+	return nil
+}
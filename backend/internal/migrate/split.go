@@ -0,0 +1,131 @@
+// backend/internal/migrate/split.go
+package migrate
+
+import "strings"
+
+// SplitStatements splits a SQL script into individual statements on
+// top-level semicolons. Semicolons inside quoted strings, quoted identifiers,
+// comments and dollar-quoted bodies ($$ ... $$, $fn$ ... $fn$) are ignored,
+// so plpgsql function definitions survive intact. Statements that contain
+// nothing but whitespace and comments are dropped.
+func SplitStatements(script string) []string {
+	var (
+		out     []string
+		current strings.Builder
+		// meaningful is set once the current statement has non-comment text.
+		meaningful bool
+	)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if meaningful && stmt != "" {
+			out = append(out, stmt)
+		}
+		current.Reset()
+		meaningful = false
+	}
+
+	for i := 0; i < len(script); {
+		ch := script[i]
+
+		switch {
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			// Postgres block comments nest.
+			depth, j := 0, i
+			for j < len(script) {
+				if strings.HasPrefix(script[j:], "/*") {
+					depth++
+					j += 2
+				} else if strings.HasPrefix(script[j:], "*/") {
+					depth--
+					j += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					j++
+				}
+			}
+			current.WriteString(script[i:j])
+			i = j
+
+		case ch == '\'' || ch == '"':
+			// A doubled quote is an escaped quote and keeps the literal open.
+			j := i + 1
+			for j < len(script) {
+				if script[j] == ch {
+					if j+1 < len(script) && script[j+1] == ch {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			current.WriteString(script[i:j])
+			meaningful = true
+			i = j
+
+		case ch == '$':
+			if tag, ok := dollarTag(script[i:]); ok {
+				end := strings.Index(script[i+len(tag):], tag)
+				j := len(script)
+				if end >= 0 {
+					j = i + len(tag) + end + len(tag)
+				}
+				current.WriteString(script[i:j])
+				meaningful = true
+				i = j
+				continue
+			}
+			current.WriteByte(ch)
+			meaningful = true
+			i++
+
+		case ch == ';':
+			current.WriteByte(ch)
+			flush()
+			i++
+
+		default:
+			current.WriteByte(ch)
+			if !isSpace(ch) {
+				meaningful = true
+			}
+			i++
+		}
+	}
+	flush()
+
+	return out
+}
+
+// dollarTag returns the opening tag ("$$" or "$name$") at the start of s.
+// Positional parameters such as $1 are not tags.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '$':
+			return s[:j+1], true
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && j > 1:
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
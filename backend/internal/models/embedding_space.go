@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// EmbeddingSpace identifies a set of mutually comparable vectors: the model
+// that produced them and the number of dimensions requested. Vectors from
+// different spaces are stored side by side but never compared.
+type EmbeddingSpace struct {
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+}
+
+func (s EmbeddingSpace) String() string {
+	return fmt.Sprintf("%s/%d", s.Model, s.Dimensions)
+}
+
+type embeddingModelSpec struct {
+	native int
+	// reducible models accept a `dimensions` parameter to shorten vectors.
+	reducible bool
+}
+
+// embeddingModelName limits model names to characters that are safe to inline
+// in SQL; vector index predicates depend on literal model names.
+var embeddingModelName = regexp.MustCompile(`^[A-Za-z0-9._:/-]+$`)
+
+var knownEmbeddingModels = map[string]embeddingModelSpec{
+	"text-embedding-ada-002": {native: 1536},
+	"text-embedding-3-small": {native: 1536, reducible: true},
+	"text-embedding-3-large": {native: 3072, reducible: true},
+}
+
+// ResolveEmbeddingSpace validates a model/dimensions pair. A zero dimensions
+// value means the model's native size; unknown models must state it.
+func ResolveEmbeddingSpace(model string, dimensions int) (EmbeddingSpace, error) {
+	if model == "" {
+		return EmbeddingSpace{}, fmt.Errorf("embedding model is required")
+	}
+	if !embeddingModelName.MatchString(model) {
+		return EmbeddingSpace{}, fmt.Errorf("embedding model %q contains unsupported characters", model)
+	}
+	if dimensions < 0 {
+		return EmbeddingSpace{}, fmt.Errorf("embedding dimensions must not be negative")
+	}
+	spec, known := knownEmbeddingModels[model]
+	switch {
+	case !known && dimensions == 0:
+		return EmbeddingSpace{}, fmt.Errorf("embedding dimensions must be set for unknown model %q", model)
+	case !known:
+		return EmbeddingSpace{Model: model, Dimensions: dimensions}, nil
+	case dimensions == 0:
+		return EmbeddingSpace{Model: model, Dimensions: spec.native}, nil
+	case dimensions > spec.native:
+		return EmbeddingSpace{}, fmt.Errorf("%s produces at most %d dimensions (got %d)", model, spec.native, dimensions)
+	case dimensions != spec.native && !spec.reducible:
+		return EmbeddingSpace{}, fmt.Errorf("%s only produces %d dimensions (got %d)", model, spec.native, dimensions)
+	}
+	return EmbeddingSpace{Model: model, Dimensions: dimensions}, nil
+}
+
+// RequestDimensions is the value to send as the provider's `dimensions`
+// parameter: zero (omitted) unless the space shortens a reducible model.
+func (s EmbeddingSpace) RequestDimensions() int {
+	spec, known := knownEmbeddingModels[s.Model]
+	if known && s.Dimensions == spec.native {
+		return 0
+	}
+	return s.Dimensions
+}
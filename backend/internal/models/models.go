@@ -2,38 +2,154 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// The gorm tags below mirror migrations/*.sql, which own the schema;
+// `go run ./cmd/migrate check` reports any drift between the two.
+
 type Document struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Title       string    `json:"title" gorm:"not null;index"`
-	Content     string    `json:"content" gorm:"type:text;not null"`
-	URL         string    `json:"url" gorm:"index"`
-	Category    string    `json:"category" gorm:"index"`
-	Tags        []string  `json:"tags" gorm:"type:text[]"`
-	Embedding   []float32 `json:"-" gorm:"-"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Title     string         `json:"title" gorm:"type:varchar(255);not null"`
+	Content   string         `json:"content" gorm:"type:text;not null"`
+	URL       string         `json:"url" gorm:"type:varchar(500)"`
+	Category  string         `json:"category" gorm:"type:varchar(100);index:idx_documents_category"`
+	Tags      pq.StringArray `json:"tags" gorm:"type:text[]"`
+	Embedding []float32      `json:"-" gorm:"-"`
+	CreatedAt time.Time      `json:"created_at" gorm:"type:timestamp;index:idx_documents_created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"type:timestamp"`
 }
 
 type UserQuery struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Query     string    `json:"query" gorm:"type:text;not null"`
-	Response  string    `json:"response" gorm:"type:text"`
-	Sources   []string  `json:"sources" gorm:"type:text[]"`
-	CreatedAt time.Time `json:"created_at"`
+	ID       uint           `json:"id" gorm:"primaryKey"`
+	Kind     string         `json:"kind,omitempty" gorm:"type:varchar(20)"` // QueryKind*
+	Query    string         `json:"query" gorm:"type:text;not null"`
+	Response string         `json:"response" gorm:"type:text"`
+	Sources  pq.StringArray `json:"sources" gorm:"type:text[]"`
+	// The fields below are only set on chat answers eligible for the answer
+	// cache; see services.AnswerCache.
+	ChatModel           string    `json:"-" gorm:"type:varchar(100)"`
+	EmbeddingModel      string    `json:"-" gorm:"type:varchar(100)"`
+	EmbeddingDimensions int       `json:"-"`
+	QueryEmbedding      Vector    `json:"-" gorm:"type:vector"`
+	SourcesFingerprint  string    `json:"-" gorm:"type:char(64)"`
+	CreatedAt           time.Time `json:"created_at" gorm:"type:timestamp;index:idx_user_queries_created_at"`
+}
+
+const (
+	QueryKindSearch = "search"
+	QueryKindChat   = "chat"
+)
+
+// UsageEvent records the tokens consumed (and their estimated cost) by a
+// single API request, attributed to whoever made it.
+type UsageEvent struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	Endpoint         string    `json:"endpoint" gorm:"type:varchar(50);not null"`
+	UserID           string    `json:"user_id,omitempty" gorm:"type:varchar(255);index:idx_usage_events_user_id"`
+	APIKeyID         string    `json:"api_key_id,omitempty" gorm:"type:varchar(64);index:idx_usage_events_api_key_id"`
+	Collection       string    `json:"collection,omitempty" gorm:"type:varchar(100);index:idx_usage_events_collection"`
+	ChatModel        string    `json:"chat_model,omitempty" gorm:"type:varchar(100)"`
+	EmbeddingModel   string    `json:"embedding_model,omitempty" gorm:"type:varchar(100)"`
+	PromptTokens     int       `json:"prompt_tokens" gorm:"not null;default:0"`
+	CompletionTokens int       `json:"completion_tokens" gorm:"not null;default:0"`
+	EmbeddingTokens  int       `json:"embedding_tokens" gorm:"not null;default:0"`
+	CostUSD          float64   `json:"cost_usd" gorm:"type:numeric(12,6);not null;default:0"`
+	CreatedAt        time.Time `json:"created_at" gorm:"type:timestamp;index:idx_usage_events_created_at"`
 }
 
+// Embedding is one document's vector in one embedding space. A document can
+// have vectors from several models at once (e.g. while re-embedding).
 type Embedding struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
-	DocumentID uint      `json:"document_id" gorm:"index"`
-	Vector     []float32 `json:"-" gorm:"type:vector(1536)"`
-	CreatedAt  time.Time `json:"created_at"`
+	DocumentID uint      `json:"document_id" gorm:"index:idx_embeddings_document_id"`
+	Model      string    `json:"model" gorm:"type:varchar(100);not null"`
+	Dimensions int       `json:"dimensions" gorm:"not null"`
+	Vector     Vector    `json:"-" gorm:"type:vector"`
+	CreatedAt  time.Time `json:"created_at" gorm:"type:timestamp"`
+}
+
+// EmbeddingSettings is a single-row table holding the embedding space search
+// runs against. Re-indexing flips it atomically once the new vectors are ready.
+type EmbeddingSettings struct {
+	ID               uint      `json:"-" gorm:"primaryKey"`
+	ActiveModel      string    `json:"active_model" gorm:"type:varchar(100);not null"`
+	ActiveDimensions int       `json:"active_dimensions" gorm:"not null"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"type:timestamp"`
+}
+
+func (EmbeddingSettings) TableName() string {
+	return "embedding_settings"
+}
+
+// Space returns the active embedding space.
+func (s EmbeddingSettings) Space() EmbeddingSpace {
+	return EmbeddingSpace{Model: s.ActiveModel, Dimensions: s.ActiveDimensions}
+}
+
+// EmbeddingCacheEntry is a previously computed vector, keyed by embedding
+// space and the SHA-256 of the normalized text it was computed from.
+type EmbeddingCacheEntry struct {
+	Model      string    `gorm:"primaryKey;type:varchar(100)"`
+	Dimensions int       `gorm:"primaryKey;autoIncrement:false"`
+	TextHash   string    `gorm:"primaryKey;type:char(64)"`
+	Vector     Vector    `gorm:"type:vector;not null"`
+	CreatedAt  time.Time `gorm:"type:timestamp"`
+}
+
+func (EmbeddingCacheEntry) TableName() string {
+	return "embedding_cache"
+}
+
+const (
+	ReindexPending   = "pending"
+	ReindexRunning   = "running"
+	ReindexPaused    = "paused"
+	ReindexFailed    = "failed"
+	ReindexCompleted = "completed"
+	ReindexCancelled = "cancelled"
+)
+
+// ReindexJob tracks re-embedding the corpus into a new embedding space. Its
+// cursor (LastDocumentID) lets an interrupted job resume where it stopped.
+type ReindexJob struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	Model              string     `json:"model" gorm:"type:varchar(100);not null"`
+	Dimensions         int        `json:"dimensions" gorm:"not null"`
+	Status             string     `json:"status" gorm:"type:varchar(20);not null"`
+	BatchSize          int        `json:"batch_size" gorm:"not null"`
+	TotalDocuments     int        `json:"total_documents" gorm:"not null;default:0"`
+	ProcessedDocuments int        `json:"processed_documents" gorm:"not null;default:0"`
+	LastDocumentID     uint       `json:"last_document_id" gorm:"not null;default:0"`
+	EmbeddingTokens    int        `json:"embedding_tokens" gorm:"not null;default:0"`
+	Error              string     `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"type:timestamp"`
+	UpdatedAt          time.Time  `json:"updated_at" gorm:"type:timestamp"`
+	StartedAt          *time.Time `json:"started_at,omitempty" gorm:"type:timestamp"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty" gorm:"type:timestamp"`
+}
+
+// Space returns the embedding space the job is building.
+func (j ReindexJob) Space() EmbeddingSpace {
+	return EmbeddingSpace{Model: j.Model, Dimensions: j.Dimensions}
+}
+
+type ReindexRequest struct {
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions" binding:"omitempty,min=1"`
+	BatchSize  int    `json:"batch_size" binding:"omitempty,min=1,max=2048"`
 }
 
 type SearchRequest struct {
 	Query string `json:"query" binding:"required,min=3"`
-	Limit int    `json:"limit" binding:"min=1,max=10" default:"5"`
+	// Limit defaults to retrieval.default_limit; a limit above
+	// retrieval.max_limit is refused.
+	Limit int `json:"limit" binding:"omitempty,min=1"`
+	// EfSearch and Probes override retrieval.index.ef_search / probes for this
+	// query, trading latency for recall.
+	EfSearch int `json:"ef_search,omitempty" binding:"omitempty,min=1,max=1000"`
+	Probes   int `json:"probes,omitempty" binding:"omitempty,min=1,max=32768"`
 }
 
 type ChatRequest struct {
@@ -54,6 +170,50 @@ type SearchResult struct {
 type ChatResponse struct {
 	Message string         `json:"message"`
 	Sources []SearchResult `json:"sources,omitempty"`
+	// Cached is set when the answer was reused from an earlier, near-identical
+	// question instead of being generated.
+	Cached bool `json:"cached"`
+}
+
+// HealthResponse is the body of GET /health.
+type HealthResponse struct {
+	Status      string `json:"status"`
+	Timestamp   int64  `json:"timestamp"`
+	Environment string `json:"environment"`
+}
+
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+	Query   string         `json:"query"`
+	Count   int            `json:"count"`
+}
+
+// MessageResponse acknowledges an operation that returns nothing else.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type PopularQueriesResponse struct {
+	Queries []UserQuery `json:"queries"`
+}
+
+// EmbeddingCacheStats counts embedding cache lookups since the process
+// started.
+type EmbeddingCacheStats struct {
+	MemoryHits    int64   `json:"memory_hits"`
+	PostgresHits  int64   `json:"postgres_hits"`
+	Misses        int64   `json:"misses"`
+	HitRatio      float64 `json:"hit_ratio"`
+	MemoryEntries int     `json:"memory_entries"`
+}
+
+type EmbeddingCacheResponse struct {
+	Enabled bool                `json:"enabled"`
+	Stats   EmbeddingCacheStats `json:"stats"`
+}
+
+type ReindexJobListResponse struct {
+	Jobs []ReindexJob `json:"jobs"`
 }
 
 type DocumentListResponse struct {
@@ -62,3 +222,136 @@ type DocumentListResponse struct {
 	Page      int        `json:"page"`
 	Limit     int        `json:"limit"`
 }
+
+// UsageSummary is one row of the usage report: totals for a principal on a day.
+type UsageSummary struct {
+	Day              time.Time `json:"day"`
+	Principal        string    `json:"principal"`
+	Requests         int64     `json:"requests"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	EmbeddingTokens  int64     `json:"embedding_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+}
+
+type UsageReportResponse struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	GroupBy string         `json:"group_by"`
+	Rows    []UsageSummary `json:"rows"`
+}
+
+// The types below are the /api/v2 request and response shapes. v1 keeps the
+// types above; see internal/api/v1.go for how they are derived.
+
+// DocumentInput is the body of POST /api/v2/documents. Unlike v1, clients
+// cannot set the ID or timestamps.
+type DocumentInput struct {
+	Title    string   `json:"title" binding:"required,max=255"`
+	Content  string   `json:"content" binding:"required"`
+	URL      string   `json:"url,omitempty" binding:"omitempty,max=500"`
+	Category string   `json:"category,omitempty" binding:"omitempty,max=100"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// SearchHit is a matching document without its full content.
+type SearchHit struct {
+	ID       uint     `json:"id"`
+	Title    string   `json:"title"`
+	URL      string   `json:"url,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags"`
+	// Snippet is the start of the document, as given to the model.
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+type SearchResponseV2 struct {
+	Query   string      `json:"query"`
+	Results []SearchHit `json:"results"`
+}
+
+type ChatRequestV2 struct {
+	Messages []Message `json:"messages" binding:"required,min=1,dive"`
+	// Stream asks for the answer as server-sent events, as from
+	// /chat/stream.
+	Stream bool `json:"stream,omitempty"`
+}
+
+// TokenUsage counts the tokens a request consumed.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	EmbeddingTokens  int `json:"embedding_tokens"`
+}
+
+type ChatResponseV2 struct {
+	Message Message `json:"message"`
+	// Sources are the documents the answer was grounded in.
+	Sources []SearchHit `json:"sources"`
+	Cached  bool        `json:"cached"`
+	Usage   TokenUsage  `json:"usage"`
+}
+
+// The payloads of the chat stream's server-sent events. usage carries a
+// TokenUsage and error an apierror.Problem.
+
+// StreamStart opens a stream. ID names it for resuming.
+type StreamStart struct {
+	ID    string `json:"id"`
+	Model string `json:"model"`
+}
+
+type StreamSources struct {
+	Sources []SearchHit `json:"sources"`
+}
+
+// StreamDelta is the next piece of the answer.
+type StreamDelta struct {
+	Content string `json:"content"`
+}
+
+type StreamDone struct {
+	FinishReason string `json:"finish_reason"`
+}
+
+// WSRequest is a message from an /api/ws client. "chat" starts answering
+// Messages under ID (the server picks one when it is empty); "cancel" stops
+// the answer with that ID.
+type WSRequest struct {
+	Type     string    `json:"type" binding:"required,oneof=chat cancel"`
+	ID       string    `json:"id" binding:"required_if=Type cancel,max=128"`
+	Messages []Message `json:"messages" binding:"required_if=Type chat,dive"`
+}
+
+// WSEvent is a message to an /api/ws client about the answer with ID: a
+// progress update or one of the chat stream events.
+type WSEvent struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Data any    `json:"data"`
+}
+
+// ChatProgress says what an answer is waiting on: "retrieving" documents,
+// then "generating" the reply.
+type ChatProgress struct {
+	Stage string `json:"stage"`
+}
+
+type Pagination struct {
+	Page    int   `json:"page"`
+	Limit   int   `json:"limit"`
+	Total   int64 `json:"total"`
+	HasMore bool  `json:"has_more"`
+}
+
+type DocumentListResponseV2 struct {
+	Documents  []Document `json:"documents"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Tables lists the models persisted in Postgres, in migration order. It is
+// what the schema drift check compares against the live database.
+func Tables() []interface{} {
+	return []interface{}{&Document{}, &Embedding{}, &UserQuery{}, &UsageEvent{}, &EmbeddingSettings{}, &ReindexJob{}, &EmbeddingCacheEntry{}}
+}
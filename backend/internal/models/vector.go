@@ -0,0 +1,74 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Vector is a pgvector value. It is sent to and read from Postgres in the
+// extension's text form ("[0.1,0.2,...]"), so it works both as a column type
+// and as a query parameter (e.g. `e.vector <=> ?`).
+type Vector []float32
+
+// GormDataType tells GORM the column type when no explicit type tag is set.
+func (Vector) GormDataType() string {
+	return "vector"
+}
+
+// Value implements driver.Valuer.
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.Grow(len(v) * 10)
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *Vector) Scan(src interface{}) error {
+	var s string
+	switch t := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return fmt.Errorf("models.Vector: cannot scan %T", src)
+	}
+
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return fmt.Errorf("models.Vector: malformed value %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if strings.TrimSpace(s) == "" {
+		*v = Vector{}
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	out := make(Vector, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return fmt.Errorf("models.Vector: element %d: %w", i, err)
+		}
+		out[i] = float32(f)
+	}
+	*v = out
+	return nil
+}
@@ -0,0 +1,292 @@
+// backend/internal/openapi/client.go
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// GenerateClient renders Go methods calling ops, for a package named pkg
+// that provides the runtime: a Client with
+//
+//	do(ctx, method, path string, query url.Values, body, out any) error
+//	stream(ctx, method, path string, query url.Values, body any) (io.ReadCloser, error)
+//
+// Body and response types, and problem (the error body), are copied into the
+// package as plain structs with their JSON tags, so importers do not depend
+// on the server's internal packages.
+func GenerateClient(pkg, generator string, problem any, ops []Operation) ([]byte, error) {
+	g := &clientTypes{imports: map[string]bool{"context": true}, seen: map[string]reflect.Type{}}
+	var methods []clientMethod
+	for _, op := range ops {
+		m := clientMethod{Operation: op, Name: op.ID}
+		path, pathParams := convertPath(op.Path)
+		m.PathFormat = path
+		m.DocPath = path
+		for _, name := range pathParams {
+			arg := pathArg{Name: goName(name, false), Type: "uint64", Value: goName(name, false)}
+			verb := "%d"
+			if p, ok := op.pathParam(name); ok && p.Type != "integer" {
+				arg.Type = "string"
+				arg.Value = "url.PathEscape(" + arg.Name + ")"
+				verb = "%s"
+				g.imports["net/url"] = true
+			}
+			m.PathFormat = strings.Replace(m.PathFormat, "{"+name+"}", verb, 1)
+			m.PathArgs = append(m.PathArgs, arg)
+			g.imports["fmt"] = true
+		}
+		for _, p := range op.Params {
+			if p.In == "path" {
+				continue
+			}
+			g.imports["net/url"] = true
+			f := paramField{Name: goName(p.Name, true), Query: p.Name, Doc: p.Description, Type: "string"}
+			if p.Type == "integer" {
+				f.Type = "int"
+				g.imports["strconv"] = true
+			}
+			m.ParamFields = append(m.ParamFields, f)
+		}
+		if op.Body != nil {
+			m.BodyType = g.typeName(reflect.TypeOf(op.Body))
+		}
+		switch {
+		case op.Response != nil:
+			// Operations that can also stream are called for JSON; the
+			// streams have routes of their own.
+			m.Stream = false
+			m.ResponseType = g.typeName(reflect.TypeOf(op.Response))
+		case op.Stream:
+			g.imports["io"] = true
+			for _, e := range op.Events {
+				g.typeName(reflect.TypeOf(e.Data))
+			}
+		}
+		methods = append(methods, m)
+	}
+	if problem != nil {
+		g.typeName(reflect.TypeOf(problem))
+	}
+
+	var paths []string
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	err := clientTemplate.Execute(&buf, map[string]any{
+		"Package":   pkg,
+		"Generator": generator,
+		"Imports":   paths,
+		"Methods":   methods,
+		"Types":     g.types,
+	})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+type clientMethod struct {
+	Operation
+	Name         string
+	PathFormat   string
+	DocPath      string
+	PathArgs     []pathArg
+	ParamFields  []paramField
+	BodyType     string
+	ResponseType string
+}
+
+type pathArg struct {
+	Name, Type, Value string
+}
+
+type paramField struct {
+	Name, Query, Doc, Type string
+}
+
+// clientTypes collects the struct types the generated methods use.
+type clientTypes struct {
+	imports map[string]bool
+	seen    map[string]reflect.Type
+	types   []clientType
+}
+
+type clientType struct {
+	Name   string
+	Source string
+	Fields []clientField
+}
+
+type clientField struct {
+	Name, Type, Tag string
+}
+
+// typeName is t as written in the generated file. Named structs are copied
+// into the file; other named types are replaced by their underlying type.
+func (g *clientTypes) typeName(t reflect.Type) string {
+	if t == timeType {
+		g.imports["time"] = true
+		return "time.Time"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + g.typeName(t.Key()) + "]" + g.typeName(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			panic(fmt.Sprintf("openapi: anonymous struct %s in client types", t))
+		}
+		g.addStruct(t)
+		return t.Name()
+	case reflect.Interface:
+		return "any"
+	}
+	return t.Kind().String()
+}
+
+func (g *clientTypes) addStruct(t reflect.Type) {
+	if seen, ok := g.seen[t.Name()]; ok {
+		if seen != t {
+			panic(fmt.Sprintf("openapi: type name %s used by %s and %s", t.Name(), seen, t))
+		}
+		return
+	}
+	g.seen[t.Name()] = t
+	ct := clientType{Name: t.Name(), Source: t.String()}
+	var fields func(reflect.Type)
+	fields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+				fields(f.Type)
+				continue
+			}
+			cf := clientField{Name: f.Name, Type: g.typeName(f.Type)}
+			if tag != "" {
+				cf.Tag = "`json:\"" + tag + "\"`"
+			}
+			ct.Fields = append(ct.Fields, cf)
+		}
+	}
+	fields(t)
+	g.types = append(g.types, ct)
+}
+
+// goName turns snake_case into an identifier, exported or not.
+func goName(s string, exported bool) string {
+	parts := strings.Split(s, "_")
+	for i, p := range parts {
+		if p == "" || (i == 0 && !exported) {
+			continue
+		}
+		if strings.EqualFold(p, "id") {
+			parts[i] = "ID"
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		parts[i] = string(r)
+	}
+	return strings.Join(parts, "")
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by {{.Generator}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{range $m := .Methods}}
+{{- if $m.ParamFields}}
+// {{$m.Name}}Params are the query parameters of {{$m.Name}}. Zero values are
+// left out.
+type {{$m.Name}}Params struct {
+{{- range $m.ParamFields}}
+{{- if .Doc}}
+	// {{.Doc}}
+{{- end}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+
+func (p *{{$m.Name}}Params) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+{{- range $m.ParamFields}}
+{{- if eq .Type "int"}}
+	if p.{{.Name}} != 0 {
+		v.Set("{{.Query}}", strconv.Itoa(p.{{.Name}}))
+	}
+{{- else}}
+	if p.{{.Name}} != "" {
+		v.Set("{{.Query}}", p.{{.Name}})
+	}
+{{- end}}
+{{- end}}
+	return v
+}
+{{end}}
+// {{$m.Name}} calls {{$m.Method}} {{$m.DocPath}}: {{$m.Summary}}.
+{{- if $m.Deprecated}}
+//
+// Deprecated: the server marks this operation deprecated.
+{{- end}}
+func (c *Client) {{$m.Name}}(ctx context.Context
+{{- range $m.PathArgs}}, {{.Name}} {{.Type}}{{end}}
+{{- if $m.ParamFields}}, params *{{$m.Name}}Params{{end}}
+{{- if $m.BodyType}}, body {{$m.BodyType}}{{end}}) (
+{{- if $m.Stream}}io.ReadCloser, error
+{{- else if $m.ResponseType}}*{{$m.ResponseType}}, error
+{{- else}}error{{end}}) {
+{{- if $m.PathArgs}}
+	path := fmt.Sprintf("{{$m.PathFormat}}"{{range $m.PathArgs}}, {{.Value}}{{end}})
+{{- else}}
+	path := "{{$m.PathFormat}}"
+{{- end}}
+{{- if $m.Stream}}
+	return c.stream(ctx, "{{$m.Method}}", path, {{if $m.ParamFields}}params.values(){{else}}nil{{end}}, {{if $m.BodyType}}body{{else}}nil{{end}})
+{{- else if $m.ResponseType}}
+	var out {{$m.ResponseType}}
+	if err := c.do(ctx, "{{$m.Method}}", path, {{if $m.ParamFields}}params.values(){{else}}nil{{end}}, {{if $m.BodyType}}body{{else}}nil{{end}}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+{{- else}}
+	return c.do(ctx, "{{$m.Method}}", path, {{if $m.ParamFields}}params.values(){{else}}nil{{end}}, {{if $m.BodyType}}body{{else}}nil{{end}}, nil)
+{{- end}}
+}
+{{end}}
+{{- range .Types}}
+// {{.Name}} mirrors {{.Source}}.
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}`))
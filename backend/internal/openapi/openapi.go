@@ -0,0 +1,370 @@
+// backend/internal/openapi/openapi.go
+
+// Package openapi describes HTTP operations and turns them into an OpenAPI
+// 3 document and a Go client. Request and response schemas are derived from
+// the Go types the handlers bind and return, so the spec follows the models
+// package instead of being maintained by hand.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Operation is one endpoint.
+type Operation struct {
+	Method string
+	// Path uses Gin syntax, e.g. /api/v1/documents/:id. Path parameters are
+	// integers unless described in Params.
+	Path string
+	// ID names the operation: the client method, and lowerCamel the
+	// operationId.
+	ID      string
+	Summary string
+	// Description adds detail the summary has no room for.
+	Description string
+	Tag         string
+	// Params are the query parameters, and any path parameters that are not
+	// integers.
+	Params []Param
+	// Body and Response are zero values of the request and success body
+	// types; nil when there is none.
+	Body     any
+	Response any
+	// Status is the success status; 0 means 200.
+	Status int
+	// Stream marks a text/event-stream response, and Events lists the
+	// events it sends. With Response set as well, the stream is an
+	// alternative the caller asks for.
+	Stream bool
+	Events []Event
+	// Auth marks operations that need a bearer token.
+	Auth       bool
+	Deprecated bool
+}
+
+// Param is a query parameter, or a path parameter when In is "path".
+type Param struct {
+	Name        string
+	In          string // query (the default) or path
+	Description string
+	Type        string // integer or string
+	Format      string // e.g. date
+	Required    bool
+	Enum        []string
+}
+
+// Event is a server-sent event: its name and a zero value of its payload.
+type Event struct {
+	Name string
+	Data any
+}
+
+// Info is the document's info object.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Build returns the OpenAPI 3.0 document for ops. problem is a zero value
+// of the error body every operation may return instead.
+func Build(info Info, problem any, ops []Operation) map[string]any {
+	b := &schemas{defs: map[string]any{}, types: map[string]reflect.Type{}, problem: problem}
+	paths := map[string]any{}
+	for _, op := range ops {
+		path, pathParams := convertPath(op.Path)
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = b.operation(op, pathParams)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.defs,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+	return doc
+}
+
+// OperationID is the operationId of op.
+func OperationID(op Operation) string {
+	r := []rune(op.ID)
+	if len(r) > 0 {
+		r[0] = unicode.ToLower(r[0])
+	}
+	return string(r)
+}
+
+func (b *schemas) operation(op Operation, pathParams []string) map[string]any {
+	out := map[string]any{
+		"operationId": OperationID(op),
+		"summary":     op.Summary,
+	}
+	if op.Description != "" {
+		out["description"] = op.Description
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	if op.Deprecated {
+		out["deprecated"] = true
+	}
+	if op.Auth {
+		out["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+	}
+
+	var params []any
+	for _, name := range pathParams {
+		p, ok := op.pathParam(name)
+		if !ok {
+			params = append(params, map[string]any{
+				"name": name, "in": "path", "required": true,
+				"schema": map[string]any{"type": "integer", "minimum": 1},
+			})
+			continue
+		}
+		p.Required = true
+		params = append(params, parameter(p))
+	}
+	for _, p := range op.Params {
+		if p.In != "path" {
+			params = append(params, parameter(p))
+		}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Body != nil {
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": b.schema(reflect.TypeOf(op.Body))},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	content := map[string]any{}
+	if op.Response != nil {
+		content["application/json"] = map[string]any{"schema": b.schema(reflect.TypeOf(op.Response))}
+	}
+	if op.Stream {
+		media := map[string]any{"schema": map[string]any{"type": "string"}}
+		if len(op.Events) > 0 {
+			// OpenAPI 3.0 cannot describe event streams; list each event's
+			// JSON payload in an extension.
+			events := map[string]any{}
+			for _, e := range op.Events {
+				events[e.Name] = b.schema(reflect.TypeOf(e.Data))
+			}
+			media["x-events"] = events
+		}
+		content["text/event-stream"] = media
+	}
+	if len(content) > 0 {
+		success["content"] = content
+	}
+	responses := map[string]any{strconv.Itoa(status): success}
+	if b.problem != nil {
+		responses["default"] = map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				"application/problem+json": map[string]any{"schema": b.schema(reflect.TypeOf(b.problem))},
+			},
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+func parameter(p Param) map[string]any {
+	schema := map[string]any{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	in := p.In
+	if in == "" {
+		in = "query"
+	}
+	param := map[string]any{"name": p.Name, "in": in, "schema": schema}
+	if p.Description != "" {
+		param["description"] = p.Description
+	}
+	if p.Required {
+		param["required"] = true
+	}
+	return param
+}
+
+// pathParam returns the description of path parameter name, if any.
+func (op Operation) pathParam(name string) (Param, bool) {
+	for _, p := range op.Params {
+		if p.In == "path" && p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// convertPath turns /documents/:id into /documents/{id}.
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// schemas derives JSON schemas from Go types. Named structs become
+// components referenced by name.
+type schemas struct {
+	defs    map[string]any
+	types   map[string]reflect.Type
+	problem any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (b *schemas) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name := t.Name()
+		if seen, ok := b.types[name]; ok && seen != t {
+			panic(fmt.Sprintf("openapi: schema name %s used by %s and %s", name, seen, t))
+		}
+		if _, ok := b.types[name]; !ok {
+			b.types[name] = t // before recursing, so self-references terminate
+			b.defs[name] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *schemas) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	b.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func (b *schemas) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, props, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := b.schema(f.Type)
+		if applyBinding(s, f.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		props[name] = s
+	}
+}
+
+// applyBinding copies gin validation rules onto s and reports whether the
+// field is required. $ref schemas are left alone.
+func applyBinding(s map[string]any, binding string) bool {
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch s["type"] {
+			case "string":
+				s[map[string]string{"min": "minLength", "max": "maxLength"}[key]] = n
+			case "array":
+				s[map[string]string{"min": "minItems", "max": "maxItems"}[key]] = n
+			case "integer", "number":
+				s[map[string]string{"min": "minimum", "max": "maximum"}[key]] = n
+			}
+		case "oneof":
+			s["enum"] = strings.Fields(value)
+		}
+	}
+	return required
+}
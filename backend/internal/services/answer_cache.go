@@ -0,0 +1,87 @@
+// backend/internal/services/answer_cache.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// AnswerCache reuses logged chat answers for near-identical questions. A
+// logged answer qualifies when it was produced by the same chat model, its
+// question embeds within the similarity threshold of the new one, and
+// retrieval for the new question returned the same documents, unchanged
+// since (same sources fingerprint). Answers older than the max age are
+// ignored.
+type AnswerCache struct {
+	queries   store.QueryLogStore
+	threshold float64
+	maxAge    time.Duration
+}
+
+// CachedAnswer is a reusable answer and how close its question was.
+type CachedAnswer struct {
+	QueryID    uint
+	Query      string
+	Response   string
+	Similarity float64
+}
+
+// NewAnswerCache returns nil when the cache is disabled; a nil cache always
+// misses.
+func NewAnswerCache(queries store.QueryLogStore, cfg config.AnswerCacheConfig) *AnswerCache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &AnswerCache{
+		queries:   queries,
+		threshold: cfg.SimilarityThreshold,
+		maxAge:    time.Duration(cfg.MaxAgeHours) * time.Hour,
+	}
+}
+
+// SourcesFingerprint identifies a set of retrieved documents at their current
+// versions, independent of ranking order.
+func SourcesFingerprint(results []models.SearchResult) string {
+	keys := make([]string, len(results))
+	for i, r := range results {
+		keys[i] = fmt.Sprintf("%d@%d", r.Document.ID, r.Document.UpdatedAt.UnixNano())
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Lookup returns the closest qualifying answer, if any.
+func (a *AnswerCache) Lookup(ctx context.Context, chatModel string, space models.EmbeddingSpace, query models.Vector, fingerprint string) (*CachedAnswer, bool, error) {
+	if a == nil || len(query) == 0 {
+		return nil, false, nil
+	}
+	hit, ok, err := a.queries.NearestAnswer(ctx, store.AnswerMatch{
+		ChatModel:   chatModel,
+		Space:       space,
+		Vector:      query,
+		Fingerprint: fingerprint,
+		Since:       time.Now().UTC().Add(-a.maxAge),
+	})
+	if err != nil || !ok || hit.Similarity < a.threshold {
+		return nil, false, err
+	}
+	return &CachedAnswer{
+		QueryID:    hit.QueryID,
+		Query:      hit.Query,
+		Response:   hit.Response,
+		Similarity: hit.Similarity,
+	}, true, nil
+}
@@ -5,78 +5,67 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
 )
 
-type ChatService struct {
-	client *openai.Client
+// ChatProvider generates chat completions. It is the subset of the OpenAI
+// client the API uses, so tests can substitute a fake.
+type ChatProvider interface {
+	CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error)
 }
 
-func NewChatService(apiKey string) *ChatService {
-	return &ChatService{
-		client: openai.NewClient(apiKey),
-	}
+// ChatStream yields streamed completion chunks until io.EOF.
+type ChatStream interface {
+	Recv() (openai.ChatCompletionStreamResponse, error)
+	Close() error
 }
 
-func (s *ChatService) GenerateResponse(messages []openai.ChatCompletionMessage) (string, error) {
-	resp, err := s.client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
-		Model:       openai.GPT3Dot5Turbo,
-		Messages:    messages,
-		Temperature: 0.7,
-		MaxTokens:   1000,
-	})
-	if err != nil {
-		return "", err
-	}
+// OpenAIChat is the provider-backed ChatProvider.
+type OpenAIChat struct {
+	client *openai.Client
+}
 
-	return resp.Choices[0].Message.Content, nil
+func NewOpenAIChat(cfg config.OpenAIConfig) *OpenAIChat {
+	return &OpenAIChat{client: NewOpenAIClient(cfg)}
 }
 
-func (s *ChatService) StreamResponse(messages []openai.ChatCompletionMessage, stream chan string) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+func (p *OpenAIChat) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	return p.client.CreateChatCompletion(ctx, req)
+}
 
-	openaiStream, err := s.client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
-		Model:       openai.GPT3Dot5Turbo,
-		Messages:    messages,
-		Temperature: 0.7,
-		MaxTokens:   1000,
-		Stream:      true,
-	})
+func (p *OpenAIChat) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
-		return err
-	}
-	defer openaiStream.Close()
-
-	for {
-		response, err := openaiStream.Recv()
-		if err != nil {
-			close(stream)
-			return err
-		}
-
-		if len(response.Choices) > 0 && response.Choices[0].Delta.Content != "" {
-			stream <- response.Choices[0].Delta.Content
-		}
+		return nil, err
 	}
+	return stream, nil
 }
 
-func FormatContextForLLM(results []map[string]interface{}) string {
+// FormatContextForLLM renders retrieved documents as context for the model,
+// each trimmed to previewChars bytes (never splitting a UTF-8 character).
+func FormatContextForLLM(results []models.SearchResult, previewChars int) string {
 	var context strings.Builder
-	context.WriteString("Relevant documentation:\n\n")
-	
 	for i, result := range results {
-		content := result["content"].(string)
-		if len(content) > 300 {
-			content = content[:300] + "..."
-		}
-		
-		context.WriteString(fmt.Sprintf("Document %d: %s\n%s\n\n", 
-			i+1, 
-			result["title"].(string), 
-			content))
+		content := Preview(result.Document.Content, previewChars)
+		context.WriteString(fmt.Sprintf("Document %d: %s\n%s\n\n", i+1, result.Document.Title, content))
 	}
-	
 	return context.String()
 }
+
+// Preview is at most the first chars bytes of content, cut at a rune
+// boundary.
+func Preview(content string, chars int) string {
+	if len(content) <= chars {
+		return content
+	}
+	cut := chars
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut]
+}
@@ -0,0 +1,94 @@
+// backend/internal/services/chunking.go
+package services
+
+import (
+	"context"
+	"math"
+	"unicode"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+// SplitText cuts text into pieces of at most cfg.Size characters, each
+// starting cfg.Overlap characters before the previous one ended. A piece
+// ends at the last whitespace in its second half when there is one, so
+// words are not cut. A zero Size leaves text whole.
+func SplitText(text string, cfg config.ChunkingConfig) []string {
+	runes := []rune(text)
+	if cfg.Size <= 0 || len(runes) <= cfg.Size {
+		return []string{text}
+	}
+	var chunks []string
+	for start := 0; ; {
+		end := start + cfg.Size
+		if end >= len(runes) {
+			return append(chunks, string(runes[start:]))
+		}
+		for i := end - 1; i > start+cfg.Size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i + 1
+				break
+			}
+		}
+		chunks = append(chunks, string(runes[start:end]))
+		// Always move forward, even if a word boundary ate the overlap.
+		start = max(end-cfg.Overlap, start+1)
+	}
+}
+
+// EmbedDocuments returns one vector per text in space. Each text is split
+// by cfg and its chunks embedded together in one call; a document's vector
+// is the normalized mean of its chunks', so it stays one row per document
+// and space. The tokens are those of every chunk.
+func EmbedDocuments(ctx context.Context, embedder Embedder, space models.EmbeddingSpace, texts []string, cfg config.ChunkingConfig) ([][]float32, int, error) {
+	var (
+		chunks []string
+		counts = make([]int, len(texts))
+	)
+	for i, text := range texts {
+		split := SplitText(text, cfg)
+		chunks = append(chunks, split...)
+		counts[i] = len(split)
+	}
+	vectors, tokens, err := embedder.Embed(ctx, space, chunks)
+	if err != nil {
+		return nil, tokens, err
+	}
+
+	out := make([][]float32, len(texts))
+	next := 0
+	for i, n := range counts {
+		if n == 1 {
+			out[i] = vectors[next]
+		} else {
+			out[i] = meanVector(vectors[next : next+n])
+		}
+		next += n
+	}
+	return out, tokens, nil
+}
+
+// meanVector averages vectors and scales the result to unit length, the
+// way the provider returns them.
+func meanVector(vectors [][]float32) []float32 {
+	sum := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for j, x := range v {
+			sum[j] += float64(x)
+		}
+	}
+	var norm float64
+	for _, x := range sum {
+		norm += x * x
+	}
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		norm = 1
+	}
+	mean := make([]float32, len(sum))
+	for j, x := range sum {
+		mean[j] = float32(x / norm)
+	}
+	return mean
+}
@@ -0,0 +1,185 @@
+// backend/internal/services/embedding_cache.go
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddingCache remembers vectors by embedding space and normalized text so
+// repeated queries and unchanged content are not embedded twice. Lookups try
+// an in-process LRU first, then the shared embedding_cache table. Cache
+// failures are logged and treated as misses; they never fail a request.
+type EmbeddingCache struct {
+	db       *gorm.DB // nil disables the Postgres tier
+	capacity int
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	order   *list.List // front = most recently used
+
+	memoryHits   atomic.Int64
+	postgresHits atomic.Int64
+	misses       atomic.Int64
+}
+
+// EmbeddingCacheStats counts lookups since the process started.
+type EmbeddingCacheStats = models.EmbeddingCacheStats
+
+type cacheKey struct {
+	space models.EmbeddingSpace
+	hash  string
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	vector []float32
+}
+
+// NewEmbeddingCache returns nil when the cache is disabled; a nil cache is
+// valid and always misses.
+func NewEmbeddingCache(db *gorm.DB, cfg config.EmbeddingCacheConfig) *EmbeddingCache {
+	if !cfg.Enabled {
+		return nil
+	}
+	c := &EmbeddingCache{
+		capacity: cfg.MemoryEntries,
+		entries:  map[cacheKey]*list.Element{},
+		order:    list.New(),
+	}
+	if cfg.Postgres {
+		c.db = db
+	}
+	return c
+}
+
+// NormalizeText is the form of a text the cache is keyed by: surrounding
+// whitespace is trimmed and internal runs collapse to one space. Case is
+// kept, since embeddings are case-sensitive.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func textHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached vector for normalized text in space.
+func (c *EmbeddingCache) Get(ctx context.Context, space models.EmbeddingSpace, normalized string) ([]float32, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := cacheKey{space: space, hash: textHash(normalized)}
+
+	if vector, ok := c.getMemory(key); ok {
+		c.memoryHits.Add(1)
+		return vector, true
+	}
+
+	if c.db != nil {
+		var entry models.EmbeddingCacheEntry
+		err := c.db.WithContext(ctx).
+			Where("model = ? AND dimensions = ? AND text_hash = ?", space.Model, space.Dimensions, key.hash).
+			Limit(1).Find(&entry).Error
+		if err != nil {
+			logging.For("cache").WarnContext(ctx, "embedding cache lookup failed", "error", err)
+		} else if len(entry.Vector) == space.Dimensions {
+			c.postgresHits.Add(1)
+			c.putMemory(key, entry.Vector)
+			return entry.Vector, true
+		}
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+// Put stores vectors computed for normalized texts in space.
+func (c *EmbeddingCache) Put(ctx context.Context, space models.EmbeddingSpace, normalized []string, vectors [][]float32) {
+	if c == nil || len(normalized) == 0 {
+		return
+	}
+	rows := make([]models.EmbeddingCacheEntry, 0, len(normalized))
+	seen := map[string]bool{}
+	for i, text := range normalized {
+		key := cacheKey{space: space, hash: textHash(text)}
+		c.putMemory(key, vectors[i])
+		if !seen[key.hash] {
+			seen[key.hash] = true
+			rows = append(rows, models.EmbeddingCacheEntry{
+				Model:      space.Model,
+				Dimensions: space.Dimensions,
+				TextHash:   key.hash,
+				Vector:     models.Vector(vectors[i]),
+			})
+		}
+	}
+
+	if c.db != nil {
+		err := c.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500).Error
+		if err != nil {
+			logging.For("cache").WarnContext(ctx, "embedding cache store failed", "error", err)
+		}
+	}
+}
+
+// Stats returns hit and miss counts.
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	if c == nil {
+		return EmbeddingCacheStats{}
+	}
+	s := EmbeddingCacheStats{
+		MemoryHits:   c.memoryHits.Load(),
+		PostgresHits: c.postgresHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+	if total := s.MemoryHits + s.PostgresHits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.MemoryHits+s.PostgresHits) / float64(total)
+	}
+	c.mu.Lock()
+	s.MemoryEntries = c.order.Len()
+	c.mu.Unlock()
+	return s
+}
+
+func (c *EmbeddingCache) getMemory(key cacheKey) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).vector, true
+}
+
+func (c *EmbeddingCache) putMemory(key cacheKey, vector []float32) {
+	if c.capacity <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).vector = vector
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
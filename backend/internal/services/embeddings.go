@@ -3,40 +3,278 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
 )
 
+// Provider limits for one embeddings request.
+const (
+	maxBatchInputs = 2048
+	maxBatchTokens = 300_000
+)
+
+// EmbeddingService produces vectors in a single embedding space.
 type EmbeddingService struct {
 	client *openai.Client
+	space  models.EmbeddingSpace
+
+	// Concurrency is how many batch requests GetEmbeddings keeps in flight.
+	Concurrency int
+	// MaxRetries is how many times a rate-limited or failed (5xx) request is
+	// retried; RetryBackoff is the first delay, doubled on every attempt.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// BatchTokens caps the estimated tokens sent in one request.
+	BatchTokens int
+	// Cache, when set, is consulted before calling the provider and filled
+	// with whatever the provider returns.
+	Cache *EmbeddingCache
 }
 
-func NewEmbeddingService(apiKey string) *EmbeddingService {
+func NewEmbeddingService(cfg config.OpenAIConfig, space models.EmbeddingSpace) *EmbeddingService {
 	return &EmbeddingService{
-		client: openai.NewClient(apiKey),
+		client:       NewOpenAIClient(cfg),
+		space:        space,
+		Concurrency:  4,
+		MaxRetries:   5,
+		RetryBackoff: 500 * time.Millisecond,
+		BatchTokens:  maxBatchTokens,
 	}
 }
 
-func (s *EmbeddingService) GetEmbedding(text string) ([]float32, error) {
-	resp, err := s.client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
-		Input: text,
-		Model: openai.AdaEmbeddingV2,
-	})
+// Embedder embeds texts in a given embedding space. Callers depend on this
+// rather than on a provider so tests can substitute a fake.
+type Embedder interface {
+	Embed(ctx context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error)
+}
+
+// OpenAIEmbedder is the provider-backed Embedder. It shares one cache across
+// every space it is asked to embed in.
+type OpenAIEmbedder struct {
+	cfg   config.OpenAIConfig
+	cache *EmbeddingCache
+}
+
+func NewOpenAIEmbedder(cfg config.OpenAIConfig, cache *EmbeddingCache) *OpenAIEmbedder {
+	return &OpenAIEmbedder{cfg: cfg, cache: cache}
+}
+
+// Embed returns one vector per text, in order, and the tokens billed.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	svc := NewEmbeddingService(e.cfg, space)
+	svc.Cache = e.cache
+	return svc.GetEmbeddings(ctx, texts)
+}
+
+// Space returns the embedding space this service produces vectors in.
+func (s *EmbeddingService) Space() models.EmbeddingSpace {
+	return s.space
+}
+
+// GetEmbedding embeds text and returns the vector with the tokens billed.
+func (s *EmbeddingService) GetEmbedding(ctx context.Context, text string) ([]float32, int, error) {
+	vectors, tokens, err := s.GetEmbeddings(ctx, []string{text})
 	if err != nil {
-		return nil, err
+		return nil, tokens, err
 	}
+	return vectors[0], tokens, nil
+}
+
+// GetEmbeddings embeds texts in as few requests as the provider allows,
+// running up to Concurrency requests at once. Vectors are returned in input
+// order along with the total tokens billed; texts served from the cache cost
+// nothing. The first failure cancels the remaining requests.
+//
+// Texts that normalize alike share a cache entry and a request, but the
+// provider is sent the text as given: normalizing is only for the key.
+func (s *EmbeddingService) GetEmbeddings(ctx context.Context, texts []string) ([][]float32, int, error) {
+	embeddings := make([][]float32, len(texts))
 
-	return resp.Data[0].Embedding, nil
+	// Look everything up first and only send the misses, once each.
+	var (
+		pending []string // the first text given for each key
+		keys    []string
+		slots   = map[string][]int{}
+	)
+	for i, text := range texts {
+		key := NormalizeText(text)
+		if vector, ok := s.Cache.Get(ctx, s.space, key); ok {
+			embeddings[i] = vector
+			continue
+		}
+		if _, queued := slots[key]; !queued {
+			pending = append(pending, text)
+			keys = append(keys, key)
+		}
+		slots[key] = append(slots[key], i)
+	}
+	if len(pending) == 0 {
+		return embeddings, 0, nil
+	}
+
+	vectors, tokens, err := s.embedAll(ctx, pending)
+	if err != nil {
+		return nil, tokens, err
+	}
+	s.Cache.Put(ctx, s.space, keys, vectors)
+	for j, key := range keys {
+		for _, i := range slots[key] {
+			embeddings[i] = vectors[j]
+		}
+	}
+	return embeddings, tokens, nil
 }
 
-func (s *EmbeddingService) GetEmbeddings(texts []string) ([][]float32, error) {
+// embedAll sends texts to the provider in concurrent batches.
+func (s *EmbeddingService) embedAll(ctx context.Context, texts []string) ([][]float32, int, error) {
 	embeddings := make([][]float32, len(texts))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		total    int
+		firstErr error
+	)
+	sem := make(chan struct{}, max(s.Concurrency, 1))
+	for _, b := range splitBatches(texts, max(s.BatchTokens, 1)) {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(b batch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			vectors, tokens, err := s.embedBatch(ctx, texts[b.start:b.end])
+			mu.Lock()
+			defer mu.Unlock()
+			total += tokens
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			copy(embeddings[b.start:b.end], vectors)
+		}(b)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, total, firstErr
+	}
+	return embeddings, total, nil
+}
+
+// batch is a half-open range of inputs sent in one request.
+type batch struct{ start, end int }
+
+// splitBatches groups consecutive texts so no request exceeds the provider's
+// input count or the token budget. A single oversized text still gets a batch
+// of its own; the provider rejects it with a clear error.
+func splitBatches(texts []string, tokenBudget int) []batch {
+	var out []batch
+	start, tokens := 0, 0
 	for i, text := range texts {
-		embedding, err := s.GetEmbedding(text)
-		if err != nil {
-			return nil, err
+		n := estimateTokens(text)
+		if i > start && (i-start >= maxBatchInputs || tokens+n > tokenBudget) {
+			out = append(out, batch{start, i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	return append(out, batch{start, len(texts)})
+}
+
+// estimateTokens approximates the token count of text without a tokenizer
+// (about four bytes per token for English). It only sizes batches.
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+// embedBatch sends one request, retrying transient failures, and returns the
+// vectors in the order of texts.
+func (s *EmbeddingService) embedBatch(ctx context.Context, texts []string) ([][]float32, int, error) {
+	req := openai.EmbeddingRequest{
+		Input:      texts,
+		Model:      openai.EmbeddingModel(s.space.Model),
+		Dimensions: s.space.RequestDimensions(),
+	}
+
+	var (
+		resp openai.EmbeddingResponse
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = s.client.CreateEmbeddings(ctx, req)
+		if err == nil || attempt >= s.MaxRetries || !retryable(err) {
+			break
 		}
-		embeddings[i] = embedding
+		select {
+		case <-time.After(backoff(s.RetryBackoff, attempt)):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, resp.Usage.PromptTokens, fmt.Errorf("embedding response contained %d vectors for %d inputs", len(resp.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) || vectors[d.Index] != nil {
+			return nil, resp.Usage.PromptTokens, fmt.Errorf("embedding response has a bad index %d", d.Index)
+		}
+		if got := len(d.Embedding); got != s.space.Dimensions {
+			return nil, resp.Usage.PromptTokens, fmt.Errorf("expected %d dimensions from %s, got %d", s.space.Dimensions, s.space.Model, got)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, resp.Usage.PromptTokens, nil
+}
+
+// retryable reports whether err is a rate limit or a server-side failure.
+func retryable(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	return false
+}
+
+// backoff returns base * 2^attempt, capped at 30s, with up to 50% jitter so
+// concurrent batches do not retry in lockstep.
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > 30*time.Second {
+		d = 30 * time.Second
 	}
-	return embeddings, nil
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
 }
@@ -0,0 +1,17 @@
+// backend/internal/services/openai.go
+package services
+
+import (
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+)
+
+// NewOpenAIClient builds a client for the configured provider, honouring a
+// custom base URL for proxies and OpenAI-compatible gateways.
+func NewOpenAIClient(cfg config.OpenAIConfig) *openai.Client {
+	clientCfg := openai.DefaultConfig(cfg.APIKey)
+	if cfg.BaseURL != "" {
+		clientCfg.BaseURL = cfg.BaseURL
+	}
+	return openai.NewClientWithConfig(clientCfg)
+}
@@ -0,0 +1,169 @@
+// backend/internal/services/reindex.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+var (
+	ErrReindexRunning = store.ErrReindexRunning
+	ErrAlreadyActive  = errors.New("embedding space is already active")
+)
+
+// reindexLease is how long a running job may go without a heartbeat before
+// another process is allowed to take it over.
+const reindexLease = 2 * time.Minute
+
+// Reindexer re-embeds the corpus into the target space next to the existing
+// vectors, then atomically makes it the active space. Search keeps using the
+// old vectors until the flip, so there is no downtime.
+type Reindexer struct {
+	store    store.ReindexStore
+	embedder Embedder
+	target   models.EmbeddingSpace
+	fallback models.EmbeddingSpace
+	// Progress, when set, is called after every batch.
+	Progress func(job models.ReindexJob)
+	// Index, when set, is used to build an ANN index for the target space
+	// before the flip, so search is fast from the moment it switches over.
+	Index *config.VectorIndexConfig
+	// Chunking splits documents before embedding, as ingestion does; the
+	// zero value embeds each document whole.
+	Chunking config.ChunkingConfig
+}
+
+// NewReindexer builds a re-indexer into target. fallback is the configured
+// space, used as the active one until a re-index has completed.
+func NewReindexer(jobs store.ReindexStore, embedder Embedder, target, fallback models.EmbeddingSpace) *Reindexer {
+	return &Reindexer{store: jobs, embedder: embedder, target: target, fallback: fallback}
+}
+
+// Start claims a job for the target space. An unfinished job for the same
+// space (paused, failed, or abandoned by a crashed process) is resumed from
+// its cursor; unfinished jobs for other spaces are cancelled.
+func (r *Reindexer) Start(ctx context.Context, batchSize int) (*models.ReindexJob, error) {
+	active, err := r.store.ActiveEmbeddingSpace(ctx, r.fallback)
+	if err != nil {
+		return nil, err
+	}
+	if active == r.target {
+		return nil, ErrAlreadyActive
+	}
+	return r.store.ClaimReindexJob(ctx, r.target, batchSize, reindexLease)
+}
+
+// Run processes job until every document has a vector in the target space and
+// the flip has been committed. If ctx is cancelled the job is paused and can
+// be resumed later with Start.
+func (r *Reindexer) Run(ctx context.Context, job *models.ReindexJob) error {
+	target := job.Space()
+	for {
+		if err := ctx.Err(); err != nil {
+			r.stop(ctx, job, models.ReindexPaused, "")
+			return err
+		}
+
+		docs, err := r.store.DocumentsWithoutEmbedding(ctx, target, job.LastDocumentID, job.BatchSize)
+		if err != nil {
+			return r.fail(ctx, job, err)
+		}
+
+		if len(docs) == 0 {
+			if err := r.ensureIndex(ctx, target); err != nil {
+				return r.fail(ctx, job, err)
+			}
+			done, err := r.store.CompleteReindex(ctx, job)
+			if err != nil {
+				return r.fail(ctx, job, err)
+			}
+			if done {
+				if r.Progress != nil {
+					r.Progress(*job)
+				}
+				return nil
+			}
+			// Something behind the cursor still lacks a vector (e.g. a write
+			// that raced the scan); sweep again from the start.
+			job.LastDocumentID = 0
+			continue
+		}
+
+		texts := make([]string, len(docs))
+		for i, d := range docs {
+			texts[i] = d.Content
+		}
+		vectors, tokens, err := EmbedDocuments(ctx, r.embedder, target, texts, r.Chunking)
+		if err != nil {
+			return r.fail(ctx, job, err)
+		}
+
+		rows := make([]models.Embedding, len(docs))
+		for i, d := range docs {
+			rows[i] = models.Embedding{
+				DocumentID: d.ID,
+				Model:      target.Model,
+				Dimensions: target.Dimensions,
+				Vector:     models.Vector(vectors[i]),
+			}
+		}
+
+		job.ProcessedDocuments += len(docs)
+		job.LastDocumentID = docs[len(docs)-1].ID
+		job.EmbeddingTokens += tokens
+		if job.ProcessedDocuments > job.TotalDocuments {
+			// Documents were added while we ran.
+			job.TotalDocuments = job.ProcessedDocuments
+		}
+
+		err = r.store.SaveReindexBatch(ctx, job, rows, &models.UsageEvent{
+			Endpoint:        "reindex",
+			EmbeddingModel:  target.Model,
+			EmbeddingTokens: tokens,
+			CostUSD:         EstimateCost(target.Model, tokens, 0),
+		})
+		if err != nil {
+			return r.fail(ctx, job, err)
+		}
+
+		if r.Progress != nil {
+			r.Progress(*job)
+		}
+	}
+}
+
+// ensureIndex builds the configured ANN index for space if it is missing.
+// Spaces too wide for pgvector to index are left to sequential scans.
+func (r *Reindexer) ensureIndex(ctx context.Context, space models.EmbeddingSpace) error {
+	if r.Index == nil || space.Dimensions > database.MaxIndexedDimensions {
+		return nil
+	}
+	return r.store.CreateVectorIndex(ctx, VectorIndexSpec(*r.Index, space))
+}
+
+func (r *Reindexer) fail(ctx context.Context, job *models.ReindexJob, err error) error {
+	if ctx.Err() != nil {
+		r.stop(ctx, job, models.ReindexPaused, "")
+		return ctx.Err()
+	}
+	r.stop(ctx, job, models.ReindexFailed, err.Error())
+	return fmt.Errorf("reindex job %d: %w", job.ID, err)
+}
+
+// stop records a terminal or paused state. It deliberately outlives the
+// run's context, which is usually what was cancelled.
+func (r *Reindexer) stop(ctx context.Context, job *models.ReindexJob, status, message string) {
+	job.Status = status
+	job.Error = message
+	if err := r.store.SetReindexJobStatus(context.WithoutCancel(ctx), job); err != nil {
+		logging.For("reindex").WarnContext(ctx, "saving reindex job failed", "job_id", job.ID, "status", status, "error", err)
+	}
+}
@@ -2,44 +2,185 @@
 package services
 
 import (
-	"gorm.io/gorm"
+	"context"
+	"fmt"
+
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+	"github.com/yourname/ai-documentation-assistant/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// SearchService is the one retrieval pipeline behind search and chat:
+// embed the query in the active embedding space, fetch the nearest
+// documents, then apply Filters and the Reranker in that order.
 type SearchService struct {
-	db *gorm.DB
-}
-
-func NewSearchService(db *gorm.DB) *SearchService {
-	return &SearchService{db: db}
-}
-
-func (s *SearchService) SearchSimilar(queryEmbedding []float32, limit int) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
-	err := s.db.Raw(`
-		SELECT 
-			d.id,
-			d.title,
-			d.content,
-			d.url,
-			d.category,
-			d.tags,
-			e.vector <=> ? AS score
-		FROM documents d
-		JOIN embeddings e ON d.id = e.document_id
-		ORDER BY e.vector <=> ?
-		LIMIT ?
-	`, queryEmbedding, queryEmbedding, limit).Scan(&results).Error
-
-	return results, err
-}
-
-type SearchResult struct {
-	ID       uint    `json:"id"`
-	Title    string  `json:"title"`
-	Content  string  `json:"content"`
-	URL      string  `json:"url"`
-	Category string  `json:"category"`
-	Tags     []string `json:"tags"`
-	Score    float64 `json:"score"`
+	documents store.DocumentStore
+	embedder  Embedder
+	fallback  models.EmbeddingSpace
+	retrieval config.RetrievalConfig
+
+	// Filters drop unwanted results, e.g. by category or distance.
+	Filters []ResultFilter
+	// Reranker, when set, reorders the filtered results.
+	Reranker Reranker
+}
+
+// ResultFilter keeps the results it returns.
+type ResultFilter func(query string, results []models.SearchResult) []models.SearchResult
+
+// Reranker reorders results for query, best first.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []models.SearchResult) ([]models.SearchResult, error)
+}
+
+// SearchQuery is one retrieval request.
+type SearchQuery struct {
+	Text  string
+	Limit int
+	// Tuning overrides retrieval.index.ef_search / probes where non-zero.
+	Tuning SearchTuning
+}
+
+// Retrieval is what a search found and what it cost. It is filled in as far
+// as the pipeline got, so callers can still account for the embedding when
+// a later step fails.
+type Retrieval struct {
+	Results         []models.SearchResult
+	Space           models.EmbeddingSpace
+	Vector          models.Vector
+	EmbeddingTokens int
+}
+
+// candidateFactor is how many extra neighbours are fetched when filters or a
+// reranker may discard or demote some of them.
+const candidateFactor = 4
+
+// NewSearchService builds the pipeline. fallback is the configured embedding
+// space, searched until a re-index has recorded another one.
+func NewSearchService(documents store.DocumentStore, embedder Embedder, fallback models.EmbeddingSpace, retrieval config.RetrievalConfig) *SearchService {
+	return &SearchService{
+		documents: documents,
+		embedder:  embedder,
+		fallback:  fallback,
+		retrieval: retrieval,
+	}
+}
+
+// Search runs the pipeline for q, traced as a "retrieval" span with the
+// query embedding and the vector search as children.
+func (s *SearchService) Search(ctx context.Context, q SearchQuery) (out Retrieval, err error) {
+	ctx, span := tracer.Start(ctx, "retrieval", trace.WithAttributes(attribute.Int("retrieval.limit", q.Limit)))
+	defer func() {
+		span.SetAttributes(attribute.Int("retrieval.results", len(out.Results)))
+		tracing.End(span, err)
+	}()
+
+	out.Space, err = s.documents.ActiveEmbeddingSpace(ctx, s.fallback)
+	if err != nil {
+		return out, err
+	}
+	span.SetAttributes(attribute.String("embedding.space", out.Space.String()))
+
+	if err = s.embedQuery(ctx, q.Text, &out); err != nil {
+		return out, fmt.Errorf("embed query: %w", err)
+	}
+
+	tuning := TuningFromConfig(s.retrieval.Index)
+	if q.Tuning.EfSearch > 0 {
+		tuning.EfSearch = q.Tuning.EfSearch
+	}
+	if q.Tuning.Probes > 0 {
+		tuning.Probes = q.Tuning.Probes
+	}
+	candidates := q.Limit
+	if len(s.Filters) > 0 || s.Reranker != nil {
+		candidates *= candidateFactor
+	}
+	results, err := s.nearest(ctx, out, candidates, tuning)
+	if err != nil {
+		return out, fmt.Errorf("retrieve: %w", err)
+	}
+
+	for _, filter := range s.Filters {
+		results = filter(q.Text, results)
+	}
+	if s.Reranker != nil {
+		if results, err = s.Reranker.Rerank(ctx, q.Text, results); err != nil {
+			return out, fmt.Errorf("rerank: %w", err)
+		}
+	}
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	out.Results = results
+	return out, nil
+}
+
+func (s *SearchService) embedQuery(ctx context.Context, text string, out *Retrieval) (err error) {
+	ctx, span := tracer.Start(ctx, "retrieval.embed_query", trace.WithAttributes(
+		tracing.AttrSystem.String("openai"),
+		tracing.AttrOperation.String("embeddings"),
+		tracing.AttrRequestModel.String(out.Space.Model),
+		attribute.Int("embedding.dimensions", out.Space.Dimensions),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	vectors, tokens, err := s.embedder.Embed(ctx, out.Space, []string{text})
+	out.EmbeddingTokens = tokens
+	span.SetAttributes(tracing.AttrInputTokens.Int(tokens))
+	if err != nil {
+		return err
+	}
+	out.Vector = models.Vector(vectors[0])
+	return nil
+}
+
+func (s *SearchService) nearest(ctx context.Context, out Retrieval, limit int, tuning SearchTuning) (results []models.SearchResult, err error) {
+	ctx, span := tracer.Start(ctx, "retrieval.vector_search", trace.WithAttributes(
+		attribute.Int("retrieval.candidates", limit),
+		attribute.Int("retrieval.ef_search", tuning.EfSearch),
+		attribute.Int("retrieval.probes", tuning.Probes),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("retrieval.rows", len(results)))
+		tracing.End(span, err)
+	}()
+	return s.documents.NearestDocuments(ctx, out.Space, out.Vector, limit, tuning)
+}
+
+// MaxDistance is a filter dropping results farther than distance from the
+// query.
+func MaxDistance(distance float64) ResultFilter {
+	return func(_ string, results []models.SearchResult) []models.SearchResult {
+		kept := results[:0]
+		for _, r := range results {
+			if r.Score <= distance {
+				kept = append(kept, r)
+			}
+		}
+		return kept
+	}
+}
+
+// SearchTuning sets the recall/latency trade-off of one approximate search.
+type SearchTuning = store.SearchTuning
+
+// TuningFromConfig returns the configured per-query defaults.
+func TuningFromConfig(cfg config.VectorIndexConfig) SearchTuning {
+	return SearchTuning{EfSearch: cfg.EfSearch, Probes: cfg.Probes}
+}
+
+// VectorIndexSpec returns the index the configuration asks for in space.
+func VectorIndexSpec(cfg config.VectorIndexConfig, space models.EmbeddingSpace) database.VectorIndexSpec {
+	return database.VectorIndexSpec{
+		Space:          space,
+		Method:         cfg.Method,
+		M:              cfg.M,
+		EfConstruction: cfg.EfConstruction,
+		Lists:          cfg.Lists,
+	}
 }
@@ -0,0 +1,95 @@
+// backend/internal/services/trace.go
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/yourname/ai-documentation-assistant/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/yourname/ai-documentation-assistant/internal/services")
+
+// TracedChat wraps next so every completion is an "llm.chat" span carrying
+// the model and token counts. A streamed completion's span lasts until the
+// stream is closed.
+func TracedChat(next ChatProvider) ChatProvider {
+	return &tracedChat{next: next}
+}
+
+type tracedChat struct {
+	next ChatProvider
+}
+
+func startLLMSpan(ctx context.Context, req openai.ChatCompletionRequest) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "llm.chat",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			tracing.AttrSystem.String("openai"),
+			tracing.AttrOperation.String("chat"),
+			tracing.AttrRequestModel.String(req.Model),
+			attribute.Bool("llm.stream", req.Stream),
+			attribute.Int("llm.messages", len(req.Messages)),
+		))
+}
+
+func setUsage(span trace.Span, model string, usage openai.Usage) {
+	span.SetAttributes(
+		tracing.AttrResponseModel.String(model),
+		tracing.AttrInputTokens.Int(usage.PromptTokens),
+		tracing.AttrOutputTokens.Int(usage.CompletionTokens),
+	)
+}
+
+func (p *tracedChat) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	ctx, span := startLLMSpan(ctx, req)
+	resp, err := p.next.CreateChatCompletion(ctx, req)
+	if err == nil {
+		setUsage(span, resp.Model, resp.Usage)
+	}
+	tracing.End(span, err)
+	return resp, err
+}
+
+func (p *tracedChat) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (ChatStream, error) {
+	ctx, span := startLLMSpan(ctx, req)
+	stream, err := p.next.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	return &tracedStream{next: stream, span: span}, nil
+}
+
+type tracedStream struct {
+	next  ChatStream
+	span  trace.Span
+	err   error
+	ended bool
+}
+
+func (s *tracedStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	resp, err := s.next.Recv()
+	switch {
+	case err == nil:
+		if resp.Usage != nil {
+			setUsage(s.span, resp.Model, *resp.Usage)
+		}
+	case !errors.Is(err, io.EOF):
+		s.err = err
+	}
+	return resp, err
+}
+
+func (s *tracedStream) Close() error {
+	err := s.next.Close()
+	if !s.ended {
+		s.ended = true
+		tracing.End(s.span, s.err)
+	}
+	return err
+}
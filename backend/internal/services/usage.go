@@ -0,0 +1,50 @@
+// backend/internal/services/usage.go
+package services
+
+import "strings"
+
+// ModelPrice is the list price of a model in USD per 1K tokens.
+type ModelPrice struct {
+	PromptPer1K     float64
+	CompletionPer1K float64
+}
+
+// modelPrices holds published list prices. Unknown models are costed at zero
+// rather than guessed, so a missing entry shows up as $0 in the usage report.
+var modelPrices = map[string]ModelPrice{
+	"gpt-3.5-turbo":          {PromptPer1K: 0.0005, CompletionPer1K: 0.0015},
+	"gpt-4":                  {PromptPer1K: 0.03, CompletionPer1K: 0.06},
+	"gpt-4-turbo":            {PromptPer1K: 0.01, CompletionPer1K: 0.03},
+	"gpt-4o":                 {PromptPer1K: 0.005, CompletionPer1K: 0.015},
+	"gpt-4o-mini":            {PromptPer1K: 0.00015, CompletionPer1K: 0.0006},
+	"text-embedding-ada-002": {PromptPer1K: 0.0001},
+	"text-embedding-3-small": {PromptPer1K: 0.00002},
+	"text-embedding-3-large": {PromptPer1K: 0.00013},
+}
+
+// LookupModelPrice returns the price for model. Dated snapshots such as
+// "gpt-3.5-turbo-0125" fall back to the longest matching base model name.
+func LookupModelPrice(model string) (ModelPrice, bool) {
+	if p, ok := modelPrices[model]; ok {
+		return p, true
+	}
+	best := ""
+	for name := range modelPrices {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return modelPrices[best], true
+}
+
+// EstimateCost returns the estimated cost in USD of a call to model.
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	p, ok := LookupModelPrice(model)
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1000*p.PromptPer1K + float64(completionTokens)/1000*p.CompletionPer1K
+}
@@ -0,0 +1,506 @@
+// backend/internal/store/memory.go
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+// Memory is a DocumentStore, QueryLogStore and ReindexStore kept in
+// process. Vector search is an exact brute-force cosine scan, so results
+// match what Postgres returns with a perfect-recall index. It is meant for
+// tests and small demos.
+type Memory struct {
+	// Now stamps created/updated times; it defaults to time.Now.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	documents  []models.Document // ordered by ID
+	embeddings []models.Embedding
+	queries    []models.UserQuery
+	usage      []models.UsageEvent
+	jobs       []models.ReindexJob // ordered by ID
+	active     models.EmbeddingSpace
+	reindexing models.EmbeddingSpace
+	// Per-table sequences, like Postgres serial columns.
+	documentSeq, embeddingSeq, querySeq, usageSeq, jobSeq uint
+}
+
+func NewMemory() *Memory {
+	return &Memory{Now: time.Now}
+}
+
+// SetActiveEmbeddingSpace records space as the active one, as a completed
+// re-index would.
+func (m *Memory) SetActiveEmbeddingSpace(space models.EmbeddingSpace) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active = space
+}
+
+// SetRunningReindexSpace records a re-index into space as in progress; the
+// zero space clears it.
+func (m *Memory) SetRunningReindexSpace(space models.EmbeddingSpace) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reindexing = space
+}
+
+// next advances a sequence. Callers hold mu.
+func next(seq *uint) uint {
+	*seq++
+	return *seq
+}
+
+func (m *Memory) ListDocuments(_ context.Context, offset, limit int) ([]models.Document, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	documents := []models.Document{}
+	for i := max(offset, 0); i < len(m.documents) && len(documents) < limit; i++ {
+		documents = append(documents, m.documents[i])
+	}
+	return documents, int64(len(m.documents)), nil
+}
+
+func (m *Memory) GetDocument(_ context.Context, id uint) (models.Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.documentIndex(id)
+	if i < 0 {
+		return models.Document{}, ErrNotFound
+	}
+	return m.documents[i], nil
+}
+
+func (m *Memory) CreateDocument(_ context.Context, doc *models.Document, embeddings []models.Embedding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active.Model != "" && !covers(embeddings, m.active) {
+		return ErrSpaceChanged
+	}
+	if running, ok := m.runningSpace(); ok && !covers(embeddings, running) {
+		return ErrSpaceChanged
+	}
+	now := m.Now()
+	doc.ID = next(&m.documentSeq)
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = now
+	}
+	if doc.UpdatedAt.IsZero() {
+		doc.UpdatedAt = now
+	}
+	m.documents = append(m.documents, *doc)
+	for i := range embeddings {
+		embeddings[i].DocumentID = doc.ID
+		m.upsertEmbedding(embeddings[i], now)
+	}
+	return nil
+}
+
+func (m *Memory) upsertEmbedding(e models.Embedding, now time.Time) {
+	for i, existing := range m.embeddings {
+		if existing.DocumentID == e.DocumentID && existing.Model == e.Model && existing.Dimensions == e.Dimensions {
+			m.embeddings[i].Vector = e.Vector
+			return
+		}
+	}
+	e.ID = next(&m.embeddingSeq)
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	m.embeddings = append(m.embeddings, e)
+}
+
+func (m *Memory) documentIndex(id uint) int {
+	i := sort.Search(len(m.documents), func(i int) bool { return m.documents[i].ID >= id })
+	if i < len(m.documents) && m.documents[i].ID == id {
+		return i
+	}
+	return -1
+}
+
+func (m *Memory) DeleteDocument(_ context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.documentIndex(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.documents = append(m.documents[:i], m.documents[i+1:]...)
+	kept := m.embeddings[:0]
+	for _, e := range m.embeddings {
+		if e.DocumentID != id {
+			kept = append(kept, e)
+		}
+	}
+	m.embeddings = kept
+	return nil
+}
+
+func (m *Memory) NearestDocuments(_ context.Context, space models.EmbeddingSpace, query models.Vector, limit int, _ SearchTuning) ([]models.SearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := []models.SearchResult{}
+	for _, e := range m.embeddings {
+		if e.Model != space.Model || e.Dimensions != space.Dimensions || len(e.Vector) != len(query) {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			Document: m.documents[m.documentIndex(e.DocumentID)],
+			Score:    cosineDistance(e.Vector, query),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score < results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (m *Memory) ActiveEmbeddingSpace(_ context.Context, fallback models.EmbeddingSpace) (models.EmbeddingSpace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active.Model == "" {
+		return fallback, nil
+	}
+	return m.active, nil
+}
+
+// RunningReindexSpace reports the space set with SetRunningReindexSpace, or
+// else that of a pending, running or paused job.
+func (m *Memory) RunningReindexSpace(context.Context) (models.EmbeddingSpace, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	space, ok := m.runningSpace()
+	return space, ok, nil
+}
+
+// runningSpace is RunningReindexSpace for callers holding mu.
+func (m *Memory) runningSpace() (models.EmbeddingSpace, bool) {
+	if m.reindexing.Model != "" {
+		return m.reindexing, true
+	}
+	for _, job := range m.jobs {
+		if slices.Contains(reindexInProgress, job.Status) {
+			return job.Space(), true
+		}
+	}
+	return models.EmbeddingSpace{}, false
+}
+
+func (m *Memory) ClaimReindexJob(_ context.Context, target models.EmbeddingSpace, batchSize int, lease time.Duration) (*models.ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.Now()
+	claimed := -1
+	for i := len(m.jobs) - 1; i >= 0; i-- {
+		job := m.jobs[i]
+		if !slices.Contains(reindexUnfinished, job.Status) {
+			continue
+		}
+		if job.Status == models.ReindexRunning && now.Sub(job.UpdatedAt) < lease {
+			return nil, ErrReindexRunning
+		}
+		if job.Space() == target && claimed < 0 {
+			claimed = i
+		}
+	}
+	// Nothing changes until no live job has been found, as in a transaction.
+	for i := range m.jobs {
+		if i != claimed && slices.Contains(reindexUnfinished, m.jobs[i].Status) {
+			m.jobs[i].Status = models.ReindexCancelled
+			m.jobs[i].UpdatedAt = now
+		}
+	}
+
+	if claimed >= 0 {
+		job := &m.jobs[claimed]
+		resumeReindexJob(job, batchSize)
+		job.UpdatedAt = now
+		claimedJob := *job
+		return &claimedJob, nil
+	}
+	job := newReindexJob(target, batchSize, len(m.documents), now)
+	job.ID = next(&m.jobSeq)
+	job.CreatedAt, job.UpdatedAt = now, now
+	m.jobs = append(m.jobs, job)
+	return &job, nil
+}
+
+func (m *Memory) ListReindexJobs(_ context.Context, limit int) ([]models.ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := []models.ReindexJob{}
+	for i := len(m.jobs) - 1; i >= 0 && len(jobs) < limit; i-- {
+		jobs = append(jobs, m.jobs[i])
+	}
+	return jobs, nil
+}
+
+func (m *Memory) GetReindexJob(_ context.Context, id uint) (models.ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return models.ReindexJob{}, ErrNotFound
+}
+
+func (m *Memory) CountReindexJobs(_ context.Context, statuses []string) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := map[string]int64{}
+	for _, job := range m.jobs {
+		if slices.Contains(statuses, job.Status) {
+			counts[job.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (m *Memory) SetReindexJobStatus(_ context.Context, job *models.ReindexJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			m.jobs[i].Status = job.Status
+			m.jobs[i].Error = job.Error
+			m.jobs[i].UpdatedAt = m.Now()
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// saveJob replaces the stored copy of job. Callers hold mu.
+func (m *Memory) saveJob(job *models.ReindexJob) error {
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			job.UpdatedAt = m.Now()
+			m.jobs[i] = *job
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *Memory) DocumentsWithoutEmbedding(_ context.Context, space models.EmbeddingSpace, afterID uint, limit int) ([]models.Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var docs []models.Document
+	for _, doc := range m.documents {
+		if len(docs) == limit {
+			break
+		}
+		if doc.ID > afterID && !m.hasEmbedding(doc.ID, space) {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// hasEmbedding reports whether document id has a vector in space. Callers
+// hold mu.
+func (m *Memory) hasEmbedding(id uint, space models.EmbeddingSpace) bool {
+	for _, e := range m.embeddings {
+		if e.DocumentID == id && e.Model == space.Model && e.Dimensions == space.Dimensions {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Memory) SaveReindexBatch(_ context.Context, job *models.ReindexJob, embeddings []models.Embedding, usage *models.UsageEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.Now()
+	if err := m.saveJob(job); err != nil {
+		return err
+	}
+	for _, e := range embeddings {
+		m.upsertEmbedding(e, now)
+	}
+	usage.ID = next(&m.usageSeq)
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = now
+	}
+	m.usage = append(m.usage, *usage)
+	return nil
+}
+
+func (m *Memory) CompleteReindex(_ context.Context, job *models.ReindexJob) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range m.documents {
+		if !m.hasEmbedding(doc.ID, job.Space()) {
+			return false, nil
+		}
+	}
+	now := m.Now()
+	job.Status = models.ReindexCompleted
+	job.CompletedAt = &now
+	if err := m.saveJob(job); err != nil {
+		job.Status = models.ReindexRunning
+		job.CompletedAt = nil
+		return false, err
+	}
+	m.active = job.Space()
+	return true, nil
+}
+
+// CreateVectorIndex does nothing: Memory always scans exactly.
+func (m *Memory) CreateVectorIndex(context.Context, database.VectorIndexSpec) error {
+	return nil
+}
+
+func (m *Memory) PruneEmbeddings(_ context.Context, keep models.EmbeddingSpace) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.embeddings[:0]
+	for _, e := range m.embeddings {
+		if e.Model == keep.Model && e.Dimensions == keep.Dimensions {
+			kept = append(kept, e)
+		}
+	}
+	removed := int64(len(m.embeddings) - len(kept))
+	m.embeddings = kept
+	return removed, nil
+}
+
+func (m *Memory) LogQuery(_ context.Context, q *models.UserQuery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q.ID = next(&m.querySeq)
+	if q.CreatedAt.IsZero() {
+		q.CreatedAt = m.Now()
+	}
+	m.queries = append(m.queries, *q)
+	return nil
+}
+
+func (m *Memory) RecentQueries(_ context.Context, limit int) ([]models.UserQuery, error) {
+	m.mu.Lock()
+	queries := append([]models.UserQuery{}, m.queries...)
+	m.mu.Unlock()
+	sort.SliceStable(queries, func(i, j int) bool {
+		return queries[i].CreatedAt.After(queries[j].CreatedAt) ||
+			queries[i].CreatedAt.Equal(queries[j].CreatedAt) && queries[i].ID > queries[j].ID
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+	return queries, nil
+}
+
+func (m *Memory) NearestAnswer(_ context.Context, match AnswerMatch) (*Answer, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var best *Answer
+	for _, q := range m.queries {
+		if q.Kind != models.QueryKindChat || len(q.QueryEmbedding) != len(match.Vector) ||
+			q.SourcesFingerprint != match.Fingerprint || q.ChatModel != match.ChatModel ||
+			q.EmbeddingModel != match.Space.Model || q.EmbeddingDimensions != match.Space.Dimensions ||
+			!q.CreatedAt.After(match.Since) {
+			continue
+		}
+		similarity := 1 - cosineDistance(q.QueryEmbedding, match.Vector)
+		if best == nil || similarity > best.Similarity {
+			best = &Answer{QueryID: q.ID, Query: q.Query, Response: q.Response, Similarity: similarity}
+		}
+	}
+	return best, best != nil, nil
+}
+
+func (m *Memory) RecordUsage(_ context.Context, ev *models.UsageEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ev.ID = next(&m.usageSeq)
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = m.Now()
+	}
+	m.usage = append(m.usage, *ev)
+	return nil
+}
+
+func (m *Memory) UsageReport(_ context.Context, groupBy string, from, to time.Time) ([]models.UsageSummary, error) {
+	if !ValidUsageGroup(groupBy) {
+		return nil, errors.New("unknown usage grouping " + groupBy)
+	}
+	type key struct {
+		day       time.Time
+		principal string
+	}
+	m.mu.Lock()
+	totals := map[key]*models.UsageSummary{}
+	for _, ev := range m.usage {
+		if ev.CreatedAt.Before(from) || !ev.CreatedAt.Before(to) {
+			continue
+		}
+		k := key{day: ev.CreatedAt.UTC().Truncate(24 * time.Hour), principal: usagePrincipal(ev, groupBy)}
+		row, ok := totals[k]
+		if !ok {
+			row = &models.UsageSummary{Day: k.day, Principal: k.principal}
+			totals[k] = row
+		}
+		row.Requests++
+		row.PromptTokens += int64(ev.PromptTokens)
+		row.CompletionTokens += int64(ev.CompletionTokens)
+		row.EmbeddingTokens += int64(ev.EmbeddingTokens)
+		row.CostUSD += ev.CostUSD
+	}
+	m.mu.Unlock()
+
+	rows := make([]models.UsageSummary, 0, len(totals))
+	for _, row := range totals {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Day.Equal(rows[j].Day) {
+			return rows[i].Day.After(rows[j].Day)
+		}
+		return rows[i].CostUSD > rows[j].CostUSD
+	})
+	return rows, nil
+}
+
+// usagePrincipal mirrors the report's COALESCE(NULLIF(column, ”), 'anonymous').
+func usagePrincipal(ev models.UsageEvent, groupBy string) string {
+	var p string
+	switch groupBy {
+	case "user":
+		p = ev.UserID
+	case "api_key":
+		p = ev.APIKeyID
+	case "collection":
+		p = ev.Collection
+	}
+	if p == "" {
+		return "anonymous"
+	}
+	return p
+}
+
+// cosineDistance matches pgvector's <=> operator. Distance to a zero vector
+// is undefined there; it is treated as maximal here.
+func cosineDistance(a, b models.Vector) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot/(math.Sqrt(na)*math.Sqrt(nb))
+}
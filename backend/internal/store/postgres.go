@@ -0,0 +1,442 @@
+// backend/internal/store/postgres.go
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Postgres is the DocumentStore, QueryLogStore and ReindexStore over the
+// application database. Vector search goes through the per-space ANN indexes.
+type Postgres struct {
+	db *gorm.DB
+}
+
+func NewPostgres(db *gorm.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+func (p *Postgres) ListDocuments(ctx context.Context, offset, limit int) ([]models.Document, int64, error) {
+	var (
+		documents []models.Document
+		total     int64
+	)
+	db := p.db.WithContext(ctx)
+	if err := db.Model(&models.Document{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&documents).Error; err != nil {
+		return nil, 0, err
+	}
+	return documents, total, nil
+}
+
+func (p *Postgres) GetDocument(ctx context.Context, id uint) (models.Document, error) {
+	var doc models.Document
+	err := p.db.WithContext(ctx).First(&doc, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return doc, ErrNotFound
+	}
+	return doc, err
+}
+
+// CreateDocument holds embedding_settings in ROW EXCLUSIVE mode, which
+// conflicts with CompleteReindex's lock: either the flip commits first and
+// the check below sees it, or the flip waits and then counts this document.
+func (p *Postgres) CreateDocument(ctx context.Context, doc *models.Document, embeddings []models.Embedding) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`LOCK TABLE embedding_settings IN ROW EXCLUSIVE MODE`).Error; err != nil {
+			return err
+		}
+		var settings models.EmbeddingSettings
+		if err := tx.Limit(1).Find(&settings).Error; err != nil {
+			return err
+		}
+		if settings.ActiveModel != "" && !covers(embeddings, settings.Space()) {
+			return ErrSpaceChanged
+		}
+		var job models.ReindexJob
+		if err := tx.Where("status IN ?", reindexInProgress).Limit(1).Find(&job).Error; err != nil {
+			return err
+		}
+		if job.ID != 0 && !covers(embeddings, job.Space()) {
+			return ErrSpaceChanged
+		}
+
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		for i := range embeddings {
+			embeddings[i].DocumentID = doc.ID
+		}
+		return UpsertEmbeddings(tx, embeddings)
+	})
+}
+
+// DeleteDocument relies on the foreign key to cascade to embeddings.
+func (p *Postgres) DeleteDocument(ctx context.Context, id uint) error {
+	res := p.db.WithContext(ctx).Delete(&models.Document{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// NearestDocuments applies tuning with SET LOCAL semantics, so it only
+// affects this query's transaction.
+func (p *Postgres) NearestDocuments(ctx context.Context, space models.EmbeddingSpace, query models.Vector, limit int, tuning SearchTuning) ([]models.SearchResult, error) {
+	type row struct {
+		models.Document
+		Score float64 `gorm:"column:score"`
+	}
+	var rows []row
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tuning.EfSearch > 0 || tuning.Probes > 0 {
+			err := tx.Exec(`SELECT set_config('hnsw.ef_search', ?, true), set_config('ivfflat.probes', ?, true)`,
+				fmt.Sprint(orDefault(tuning.EfSearch, 40)), fmt.Sprint(orDefault(tuning.Probes, 1))).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Raw(`
+			SELECT d.*, `+distanceExpr(space)+` AS score
+			FROM documents d
+			JOIN embeddings e ON d.id = e.document_id
+			WHERE `+database.SpacePredicate("e", space)+`
+			ORDER BY `+distanceExpr(space)+`
+			LIMIT ?
+		`, query, query, limit).Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(rows))
+	for _, r := range rows {
+		results = append(results, models.SearchResult{
+			Document: r.Document,
+			Score:    r.Score,
+		})
+	}
+	return results, nil
+}
+
+func (p *Postgres) ActiveEmbeddingSpace(ctx context.Context, fallback models.EmbeddingSpace) (models.EmbeddingSpace, error) {
+	var settings models.EmbeddingSettings
+	if err := p.db.WithContext(ctx).Limit(1).Find(&settings).Error; err != nil {
+		return fallback, err
+	}
+	if settings.ActiveModel == "" {
+		return fallback, nil
+	}
+	return settings.Space(), nil
+}
+
+func (p *Postgres) RunningReindexSpace(ctx context.Context) (models.EmbeddingSpace, bool, error) {
+	var job models.ReindexJob
+	err := p.db.WithContext(ctx).
+		Where("status IN ?", reindexInProgress).
+		Limit(1).Find(&job).Error
+	if err != nil || job.ID == 0 {
+		return models.EmbeddingSpace{}, false, err
+	}
+	return job.Space(), true, nil
+}
+
+func (p *Postgres) ClaimReindexJob(ctx context.Context, target models.EmbeddingSpace, batchSize int, lease time.Duration) (*models.ReindexJob, error) {
+	var claimed models.ReindexJob
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []models.ReindexJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status IN ?", reindexUnfinished).
+			Order("id DESC").Find(&jobs).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, job := range jobs {
+			if job.Status == models.ReindexRunning && now.Sub(job.UpdatedAt) < lease {
+				return ErrReindexRunning
+			}
+			if job.Space() == target && claimed.ID == 0 {
+				claimed = job
+				continue
+			}
+			if err := tx.Model(&job).Update("status", models.ReindexCancelled).Error; err != nil {
+				return err
+			}
+		}
+
+		if claimed.ID != 0 {
+			resumeReindexJob(&claimed, batchSize)
+			return tx.Save(&claimed).Error
+		}
+
+		var total int64
+		if err := tx.Model(&models.Document{}).Count(&total).Error; err != nil {
+			return err
+		}
+		claimed = newReindexJob(target, batchSize, int(total), now)
+		return tx.Create(&claimed).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &claimed, nil
+}
+
+func (p *Postgres) ListReindexJobs(ctx context.Context, limit int) ([]models.ReindexJob, error) {
+	jobs := []models.ReindexJob{}
+	err := p.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+func (p *Postgres) GetReindexJob(ctx context.Context, id uint) (models.ReindexJob, error) {
+	var job models.ReindexJob
+	err := p.db.WithContext(ctx).First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, ErrNotFound
+	}
+	return job, err
+}
+
+func (p *Postgres) CountReindexJobs(ctx context.Context, statuses []string) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := p.db.WithContext(ctx).Model(&models.ReindexJob{}).
+		Select("status, COUNT(*) AS count").
+		Where("status IN ?", statuses).
+		Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
+func (p *Postgres) SetReindexJobStatus(ctx context.Context, job *models.ReindexJob) error {
+	return p.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":     job.Status,
+		"error":      job.Error,
+		"updated_at": time.Now().UTC(),
+	}).Error
+}
+
+func (p *Postgres) DocumentsWithoutEmbedding(ctx context.Context, space models.EmbeddingSpace, afterID uint, limit int) ([]models.Document, error) {
+	var docs []models.Document
+	err := p.db.WithContext(ctx).Raw(`
+		SELECT d.*
+		FROM documents d
+		WHERE d.id > ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM embeddings e
+			WHERE e.document_id = d.id AND e.model = ? AND e.dimensions = ?
+		  )
+		ORDER BY d.id
+		LIMIT ?
+	`, afterID, space.Model, space.Dimensions, limit).Scan(&docs).Error
+	return docs, err
+}
+
+func (p *Postgres) SaveReindexBatch(ctx context.Context, job *models.ReindexJob, embeddings []models.Embedding, usage *models.UsageEvent) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := UpsertEmbeddings(tx, embeddings); err != nil {
+			return err
+		}
+		if err := tx.Create(usage).Error; err != nil {
+			return err
+		}
+		return tx.Save(job).Error
+	})
+}
+
+// CompleteReindex checks and switches in one transaction holding a lock on
+// embedding_settings, so search never sees a partial corpus.
+func (p *Postgres) CompleteReindex(ctx context.Context, job *models.ReindexJob) (bool, error) {
+	target := job.Space()
+	done := false
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`LOCK TABLE embedding_settings IN SHARE ROW EXCLUSIVE MODE`).Error; err != nil {
+			return err
+		}
+
+		var missing int64
+		err := tx.Raw(`
+			SELECT COUNT(*)
+			FROM documents d
+			WHERE NOT EXISTS (
+				SELECT 1 FROM embeddings e
+				WHERE e.document_id = d.id AND e.model = ? AND e.dimensions = ?
+			)
+		`, target.Model, target.Dimensions).Scan(&missing).Error
+		if err != nil || missing > 0 {
+			return err
+		}
+
+		err = tx.Exec(`
+			INSERT INTO embedding_settings (id, active_model, active_dimensions, updated_at)
+			VALUES (1, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (id) DO UPDATE
+			SET active_model = EXCLUDED.active_model,
+			    active_dimensions = EXCLUDED.active_dimensions,
+			    updated_at = EXCLUDED.updated_at
+		`, target.Model, target.Dimensions).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		job.Status = models.ReindexCompleted
+		job.CompletedAt = &now
+		if err := tx.Save(job).Error; err != nil {
+			return err
+		}
+		done = true
+		return nil
+	})
+	if err != nil {
+		// The transaction rolled back; the job is not complete.
+		job.Status = models.ReindexRunning
+		job.CompletedAt = nil
+		return false, err
+	}
+	return done, nil
+}
+
+func (p *Postgres) CreateVectorIndex(ctx context.Context, spec database.VectorIndexSpec) error {
+	return database.CreateVectorIndex(ctx, p.db, spec)
+}
+
+func (p *Postgres) PruneEmbeddings(ctx context.Context, keep models.EmbeddingSpace) (int64, error) {
+	res := p.db.WithContext(ctx).Exec(`DELETE FROM embeddings WHERE NOT (model = ? AND dimensions = ?)`, keep.Model, keep.Dimensions)
+	return res.RowsAffected, res.Error
+}
+
+func (p *Postgres) LogQuery(ctx context.Context, q *models.UserQuery) error {
+	return p.db.WithContext(ctx).Create(q).Error
+}
+
+func (p *Postgres) RecentQueries(ctx context.Context, limit int) ([]models.UserQuery, error) {
+	queries := []models.UserQuery{}
+	err := p.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&queries).Error
+	return queries, err
+}
+
+func (p *Postgres) NearestAnswer(ctx context.Context, m AnswerMatch) (*Answer, bool, error) {
+	var hit Answer
+	distance := fmt.Sprintf("(q.query_embedding::vector(%d)) <=> ?::vector(%d)", m.Space.Dimensions, m.Space.Dimensions)
+	err := p.db.WithContext(ctx).Raw(`
+		SELECT q.id AS query_id, q.query, q.response, 1 - (`+distance+`) AS similarity
+		FROM user_queries q
+		WHERE q.kind = ?
+		  AND q.query_embedding IS NOT NULL
+		  AND q.sources_fingerprint = ?
+		  AND q.chat_model = ?
+		  AND q.embedding_model = ? AND q.embedding_dimensions = ?
+		  AND q.created_at > ?
+		ORDER BY `+distance+`
+		LIMIT 1
+	`, m.Vector, models.QueryKindChat, m.Fingerprint, m.ChatModel, m.Space.Model, m.Space.Dimensions,
+		m.Since, m.Vector).Scan(&hit).Error
+	if err != nil {
+		return nil, false, err
+	}
+	if hit.QueryID == 0 {
+		return nil, false, nil
+	}
+	return &hit, true, nil
+}
+
+func (p *Postgres) RecordUsage(ctx context.Context, ev *models.UsageEvent) error {
+	return p.db.WithContext(ctx).Create(ev).Error
+}
+
+func (p *Postgres) UsageReport(ctx context.Context, groupBy string, from, to time.Time) ([]models.UsageSummary, error) {
+	column, ok := usageGroupColumns[groupBy]
+	if !ok {
+		return nil, errors.New("unknown usage grouping " + groupBy)
+	}
+	rows := []models.UsageSummary{}
+	err := p.db.WithContext(ctx).Raw(`
+		SELECT
+			date_trunc('day', created_at) AS day,
+			COALESCE(NULLIF(`+column+`, ''), 'anonymous') AS principal,
+			COUNT(*) AS requests,
+			COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+			COALESCE(SUM(embedding_tokens), 0) AS embedding_tokens,
+			COALESCE(SUM(cost_usd), 0) AS cost_usd
+		FROM usage_events
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY 1, 2
+		ORDER BY 1 DESC, cost_usd DESC
+	`, from, to).Scan(&rows).Error
+	return rows, err
+}
+
+// UpsertEmbeddings stores vectors, replacing any existing vector for the same
+// document in the same space.
+func UpsertEmbeddings(db *gorm.DB, embeddings []models.Embedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "document_id"}, {Name: "model"}, {Name: "dimensions"}},
+		DoUpdates: clause.AssignmentColumns([]string{"vector"}),
+	}).Create(&embeddings).Error
+}
+
+// newReindexJob is a fresh job into target over total documents.
+func newReindexJob(target models.EmbeddingSpace, batchSize, total int, now time.Time) models.ReindexJob {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return models.ReindexJob{
+		Model:          target.Model,
+		Dimensions:     target.Dimensions,
+		Status:         models.ReindexRunning,
+		BatchSize:      batchSize,
+		TotalDocuments: total,
+		StartedAt:      &now,
+	}
+}
+
+// resumeReindexJob restarts an unfinished job from its cursor.
+func resumeReindexJob(job *models.ReindexJob, batchSize int) {
+	job.Status = models.ReindexRunning
+	job.Error = ""
+	if batchSize > 0 {
+		job.BatchSize = batchSize
+	}
+}
+
+// distanceExpr is the cosine distance between a stored vector and a bound
+// query vector, written to match the space's partial index.
+func distanceExpr(space models.EmbeddingSpace) string {
+	return fmt.Sprintf("%s <=> ?::vector(%d)", database.VectorExpr("e", space), space.Dimensions)
+}
+
+// orDefault returns v, or def if v is unset. def matches pgvector's own
+// default so an unset knob behaves as if it had not been touched.
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
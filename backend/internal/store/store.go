@@ -0,0 +1,168 @@
+// backend/internal/store/store.go
+
+// Package store is the persistence behind the API and services. Postgres is
+// the production implementation; Memory keeps everything in process so code
+// above it can be tested without a database.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrReindexRunning is returned when another process holds a live
+	// re-index job.
+	ErrReindexRunning = errors.New("another reindex job is running")
+	// ErrSpaceChanged is returned by CreateDocument when the embeddings
+	// miss the active space or the one a running re-index is building,
+	// because either changed after the caller looked them up.
+	ErrSpaceChanged = errors.New("embedding spaces changed")
+)
+
+// DocumentStore holds documents, their vectors and the embedding space
+// search runs against.
+type DocumentStore interface {
+	ListDocuments(ctx context.Context, offset, limit int) ([]models.Document, int64, error)
+	// GetDocument returns one document, or ErrNotFound.
+	GetDocument(ctx context.Context, id uint) (models.Document, error)
+	// CreateDocument stores doc and its embeddings together; the embeddings'
+	// DocumentID is filled in. It returns ErrSpaceChanged, storing nothing,
+	// unless the embeddings cover the active space and any running
+	// re-index's, checked atomically with CompleteReindex.
+	CreateDocument(ctx context.Context, doc *models.Document, embeddings []models.Embedding) error
+	// DeleteDocument removes a document and its embeddings, or returns
+	// ErrNotFound.
+	DeleteDocument(ctx context.Context, id uint) error
+
+	// NearestDocuments returns the limit documents closest to query in
+	// space, with their cosine distance as the score.
+	NearestDocuments(ctx context.Context, space models.EmbeddingSpace, query models.Vector, limit int, tuning SearchTuning) ([]models.SearchResult, error)
+
+	// ActiveEmbeddingSpace returns the space recorded by the last completed
+	// re-index, or fallback if none has run.
+	ActiveEmbeddingSpace(ctx context.Context, fallback models.EmbeddingSpace) (models.EmbeddingSpace, error)
+	// RunningReindexSpace returns the space an in-progress re-index is
+	// building, if any.
+	RunningReindexSpace(ctx context.Context) (models.EmbeddingSpace, bool, error)
+}
+
+// QueryLogStore holds the query log and usage accounting.
+type QueryLogStore interface {
+	LogQuery(ctx context.Context, q *models.UserQuery) error
+	// RecentQueries returns the latest logged queries, newest first.
+	RecentQueries(ctx context.Context, limit int) ([]models.UserQuery, error)
+	// NearestAnswer returns the logged chat answer whose question is closest
+	// to m.Vector among those matching m, if any.
+	NearestAnswer(ctx context.Context, m AnswerMatch) (*Answer, bool, error)
+
+	RecordUsage(ctx context.Context, ev *models.UsageEvent) error
+	// UsageReport totals usage per day and principal for [from, to).
+	// groupBy must satisfy ValidUsageGroup.
+	UsageReport(ctx context.Context, groupBy string, from, to time.Time) ([]models.UsageSummary, error)
+}
+
+// covers reports whether one of embeddings is in space.
+func covers(embeddings []models.Embedding, space models.EmbeddingSpace) bool {
+	for _, e := range embeddings {
+		if e.Model == space.Model && e.Dimensions == space.Dimensions {
+			return true
+		}
+	}
+	return false
+}
+
+// ReindexStore holds re-index jobs and the embedding settings that record
+// which space search uses. A job fills the target space next to the active
+// one, batch by batch, and CompleteReindex flips the settings over to it.
+type ReindexStore interface {
+	// ActiveEmbeddingSpace is DocumentStore's: the space recorded by the
+	// last completed re-index, or fallback if none has run.
+	ActiveEmbeddingSpace(ctx context.Context, fallback models.EmbeddingSpace) (models.EmbeddingSpace, error)
+
+	// ClaimReindexJob marks a job for target running and returns it. An
+	// unfinished job for target is resumed from its cursor, and unfinished
+	// jobs for other spaces are cancelled. It returns ErrReindexRunning if a
+	// running job has saved progress within lease.
+	ClaimReindexJob(ctx context.Context, target models.EmbeddingSpace, batchSize int, lease time.Duration) (*models.ReindexJob, error)
+	// ListReindexJobs returns the latest jobs, newest first.
+	ListReindexJobs(ctx context.Context, limit int) ([]models.ReindexJob, error)
+	// GetReindexJob returns one job, or ErrNotFound.
+	GetReindexJob(ctx context.Context, id uint) (models.ReindexJob, error)
+	// CountReindexJobs counts the jobs in each of statuses.
+	CountReindexJobs(ctx context.Context, statuses []string) (map[string]int64, error)
+	// SetReindexJobStatus records job's status and error. Its progress stays
+	// as SaveReindexBatch last saved it, so a failed batch is redone.
+	SetReindexJobStatus(ctx context.Context, job *models.ReindexJob) error
+
+	// DocumentsWithoutEmbedding returns up to limit documents after afterID
+	// that have no vector in space, in ID order.
+	DocumentsWithoutEmbedding(ctx context.Context, space models.EmbeddingSpace, afterID uint, limit int) ([]models.Document, error)
+	// SaveReindexBatch stores a batch's vectors and usage together with the
+	// job's progress, so a resumed job never redoes or skips a batch. Every
+	// save is the job's heartbeat.
+	SaveReindexBatch(ctx context.Context, job *models.ReindexJob, embeddings []models.Embedding, usage *models.UsageEvent) error
+	// CompleteReindex makes job's space the active one and the job
+	// completed if, and only if, every document has a vector in it, and
+	// reports whether it did.
+	CompleteReindex(ctx context.Context, job *models.ReindexJob) (bool, error)
+	// CreateVectorIndex builds the ANN index spec describes if it is
+	// missing. Exact implementations have nothing to build.
+	CreateVectorIndex(ctx context.Context, spec database.VectorIndexSpec) error
+	// PruneEmbeddings deletes vectors from every space except keep and
+	// returns how many were removed.
+	PruneEmbeddings(ctx context.Context, keep models.EmbeddingSpace) (int64, error)
+}
+
+// reindexUnfinished are the states of a job that can still be resumed.
+var reindexUnfinished = []string{models.ReindexPending, models.ReindexRunning, models.ReindexPaused, models.ReindexFailed}
+
+// reindexInProgress are the states in which new documents must also be
+// embedded in the job's space.
+var reindexInProgress = []string{models.ReindexPending, models.ReindexRunning, models.ReindexPaused}
+
+// SearchTuning sets the recall/latency trade-off of one approximate search:
+// hnsw.ef_search for HNSW indexes and ivfflat.probes for IVFFlat ones. Zero
+// leaves a setting alone. Exact implementations ignore it.
+type SearchTuning struct {
+	EfSearch int
+	Probes   int
+}
+
+// AnswerMatch selects logged chat answers that may be reused: same chat
+// model, same embedding space, same sources fingerprint, logged after Since.
+type AnswerMatch struct {
+	ChatModel   string
+	Space       models.EmbeddingSpace
+	Vector      models.Vector
+	Fingerprint string
+	Since       time.Time
+}
+
+// Answer is a logged chat answer and how similar its question was.
+type Answer struct {
+	QueryID    uint
+	Query      string
+	Response   string
+	Similarity float64
+}
+
+// usageGroupColumns whitelists the principal columns the usage report can
+// group by.
+var usageGroupColumns = map[string]string{
+	"user":       "user_id",
+	"api_key":    "api_key_id",
+	"collection": "collection",
+}
+
+// ValidUsageGroup reports whether the usage report can group by groupBy.
+func ValidUsageGroup(groupBy string) bool {
+	_, ok := usageGroupColumns[groupBy]
+	return ok
+}
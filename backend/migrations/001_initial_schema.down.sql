@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_documents_updated_at ON documents;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+DROP TABLE IF EXISTS user_queries;
+DROP TABLE IF EXISTS embeddings;
+DROP TABLE IF EXISTS documents;
@@ -45,7 +45,9 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
--- Create trigger for documents table
+-- Create trigger for documents table (dropped first so databases created
+-- before schema_migrations existed can adopt this migration)
+DROP TRIGGER IF EXISTS update_documents_updated_at ON documents;
 CREATE TRIGGER update_documents_updated_at 
     BEFORE UPDATE ON documents 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
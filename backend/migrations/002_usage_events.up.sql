@@ -0,0 +1,20 @@
+-- Per-request token usage and estimated cost
+CREATE TABLE IF NOT EXISTS usage_events (
+    id SERIAL PRIMARY KEY,
+    endpoint VARCHAR(50) NOT NULL,
+    user_id VARCHAR(255),
+    api_key_id VARCHAR(64),
+    collection VARCHAR(100),
+    chat_model VARCHAR(100),
+    embedding_model VARCHAR(100),
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    embedding_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_events_created_at ON usage_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_events_user_id ON usage_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_usage_events_api_key_id ON usage_events(api_key_id);
+CREATE INDEX IF NOT EXISTS idx_usage_events_collection ON usage_events(collection);
@@ -0,0 +1,9 @@
+-- Only ada-002 vectors fit the original fixed-size column.
+DELETE FROM embeddings WHERE model <> 'text-embedding-ada-002' OR dimensions <> 1536;
+
+DROP INDEX IF EXISTS idx_embeddings_model_dimensions;
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS embeddings_document_space_key;
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS embeddings_vector_dimensions_check;
+ALTER TABLE embeddings ALTER COLUMN vector TYPE vector(1536);
+ALTER TABLE embeddings DROP COLUMN IF EXISTS dimensions;
+ALTER TABLE embeddings DROP COLUMN IF EXISTS model;
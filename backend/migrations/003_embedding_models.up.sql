@@ -0,0 +1,23 @@
+-- Tag every vector with the model and size that produced it so vectors from
+-- several embedding models can live side by side. Existing rows were all
+-- produced by text-embedding-ada-002 at 1536 dimensions.
+ALTER TABLE embeddings ADD COLUMN IF NOT EXISTS model VARCHAR(100) NOT NULL DEFAULT 'text-embedding-ada-002';
+ALTER TABLE embeddings ADD COLUMN IF NOT EXISTS dimensions INTEGER NOT NULL DEFAULT 1536;
+
+-- New rows must say where they came from.
+ALTER TABLE embeddings ALTER COLUMN model DROP DEFAULT;
+ALTER TABLE embeddings ALTER COLUMN dimensions DROP DEFAULT;
+
+-- Drop the fixed size so other dimensions can be stored; the check keeps
+-- each row honest about its size.
+ALTER TABLE embeddings ALTER COLUMN vector TYPE vector;
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS embeddings_vector_dimensions_check;
+ALTER TABLE embeddings ADD CONSTRAINT embeddings_vector_dimensions_check
+    CHECK (vector IS NULL OR vector_dims(vector) = dimensions);
+
+-- One vector per document per embedding space.
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS embeddings_document_space_key;
+ALTER TABLE embeddings ADD CONSTRAINT embeddings_document_space_key
+    UNIQUE (document_id, model, dimensions);
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_model_dimensions ON embeddings(model, dimensions);
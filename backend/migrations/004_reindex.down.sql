@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reindex_jobs;
+DROP TABLE IF EXISTS embedding_settings;
@@ -0,0 +1,30 @@
+-- The embedding space search runs against. Absent until the first re-index
+-- completes, in which case the configured embedding model is used.
+CREATE TABLE IF NOT EXISTS embedding_settings (
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    active_model VARCHAR(100) NOT NULL,
+    active_dimensions INTEGER NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Re-embedding jobs and their resume cursor.
+CREATE TABLE IF NOT EXISTS reindex_jobs (
+    id SERIAL PRIMARY KEY,
+    model VARCHAR(100) NOT NULL,
+    dimensions INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    batch_size INTEGER NOT NULL,
+    total_documents INTEGER NOT NULL DEFAULT 0,
+    processed_documents INTEGER NOT NULL DEFAULT 0,
+    last_document_id INTEGER NOT NULL DEFAULT 0,
+    embedding_tokens INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- At most one unfinished job at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reindex_jobs_unfinished
+    ON reindex_jobs ((true)) WHERE status IN ('pending', 'running', 'paused');
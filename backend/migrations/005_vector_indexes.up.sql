@@ -0,0 +1,10 @@
+-- migrate:no-transaction
+-- HNSW index for the default embedding space so search stops scanning every
+-- vector. embeddings.vector is untyped (it holds several spaces), so the index
+-- is on a cast to the space's size and limited to that space's rows; queries
+-- must use the same expression and predicate to hit it. Indexes for other
+-- spaces are managed with cmd/vectorindex.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_embeddings_hnsw_text_embedding_ada_002_1536
+    ON embeddings USING hnsw ((vector::vector(1536)) vector_cosine_ops)
+    WITH (m = 16, ef_construction = 64)
+    WHERE model = 'text-embedding-ada-002' AND dimensions = 1536;
@@ -0,0 +1,12 @@
+-- Shared tier of the embedding cache: vectors for previously embedded text,
+-- keyed by embedding space and a SHA-256 of the normalized text.
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    model VARCHAR(100) NOT NULL,
+    dimensions INTEGER NOT NULL,
+    text_hash CHAR(64) NOT NULL,
+    vector vector NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model, dimensions, text_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_cache_created_at ON embedding_cache(created_at);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_user_queries_answer_cache;
+
+ALTER TABLE user_queries DROP COLUMN IF EXISTS sources_fingerprint;
+ALTER TABLE user_queries DROP COLUMN IF EXISTS query_embedding;
+ALTER TABLE user_queries DROP COLUMN IF EXISTS embedding_dimensions;
+ALTER TABLE user_queries DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE user_queries DROP COLUMN IF EXISTS chat_model;
+ALTER TABLE user_queries DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,13 @@
+-- Lets logged chat answers be reused for near-identical questions: the query
+-- vector (and the space it lives in), the chat model that answered, and a
+-- fingerprint of the documents the answer was grounded in.
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS kind VARCHAR(20);
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS chat_model VARCHAR(100);
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100);
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS embedding_dimensions INTEGER;
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS query_embedding vector;
+ALTER TABLE user_queries ADD COLUMN IF NOT EXISTS sources_fingerprint CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_user_queries_answer_cache
+    ON user_queries(sources_fingerprint, embedding_model, embedding_dimensions, chat_model)
+    WHERE kind = 'chat' AND query_embedding IS NOT NULL;
@@ -0,0 +1,10 @@
+// Package migrations embeds the numbered SQL migrations so the migrate
+// command works regardless of the working directory it is run from.
+//
+// Files are named NNN_description.up.sql / NNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
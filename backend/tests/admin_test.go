@@ -0,0 +1,69 @@
+// backend/tests/admin_test.go
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+func TestAdminReindexOnMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.Auth.JWTSecret = wsSecret
+	h, mem := newTestServerWith(t, cfg, &fakeChat{reply: "ok"})
+	for _, title := range []string{"Buttons", "Forms", "Tables"} {
+		w := doJSON(h, "POST", "/api/documents", `{"title":"`+title+`","content":"About `+title+`.","url":"https://docs/`+title+`"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	token := map[string]string{"Authorization": bearer(t, jwt.MapClaims{"sub": "admin"})}
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(h, "GET", "/api/admin/reindex", "").Code)
+	w := doWithHeaders(h, "POST", "/api/admin/reindex", `{"model":"text-embedding-ada-002"}`, token)
+	assert.Equal(t, http.StatusConflict, w.Code, "the configured space is already active")
+
+	w = doWithHeaders(h, "POST", "/api/admin/reindex", `{"model":"text-embedding-3-small","dimensions":256,"batch_size":2}`, token)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var job models.ReindexJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 3, job.TotalDocuments)
+
+	require.Eventually(t, func() bool {
+		w := doWithHeaders(h, "GET", "/api/admin/reindex/"+strconv.FormatUint(uint64(job.ID), 10), "", token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		return job.Status == models.ReindexCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, job.ProcessedDocuments)
+	assert.Equal(t, uint(3), job.LastDocumentID)
+
+	w = doWithHeaders(h, "GET", "/api/admin/reindex", "", token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list models.ReindexJobListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, job.ID, list.Jobs[0].ID)
+
+	assert.Equal(t, http.StatusNotFound, doWithHeaders(h, "GET", "/api/admin/reindex/99", "", token).Code)
+	assert.Equal(t, http.StatusBadRequest, doWithHeaders(h, "GET", "/api/admin/reindex/abc", "", token).Code)
+
+	active, err := mem.ActiveEmbeddingSpace(context.Background(), models.EmbeddingSpace{})
+	require.NoError(t, err)
+	assert.Equal(t, models.EmbeddingSpace{Model: "text-embedding-3-small", Dimensions: 256}, active)
+	w = doJSON(h, "POST", "/api/v2/search", `{"query":"buttons"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var found models.SearchResponseV2
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	assert.Len(t, found.Results, 3, "search finds every document in the new space")
+}
@@ -2,12 +2,26 @@
 package tests
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
 )
 
 func TestHealthEndpoint(t *testing.T) {
@@ -23,3 +37,238 @@ func TestHealthEndpoint(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "healthy")
 }
+
+// fakeEmbedder puts every text on the same direction, so all documents are
+// equally close to any query.
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(_ context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float32, space.Dimensions)
+		vectors[i][0] = 1
+	}
+	return vectors, len(texts), nil
+}
+
+// fakeChat answers with a fixed reply and remembers the last prompt.
+type fakeChat struct {
+	reply string
+	err   error // returned instead of a reply when set
+	last  []openai.ChatCompletionMessage
+
+	// Streams send chunks (or reply in one piece), then usage, then EOF or
+	// streamErr. With hold set, each chunk waits for a receive from it.
+	chunks    []string
+	streamErr error
+	hold      chan struct{}
+
+	mu        sync.Mutex
+	streamCtx context.Context
+}
+
+func (f *fakeChat) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.last = req.Messages
+	if err := ctx.Err(); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	if f.err != nil {
+		return openai.ChatCompletionResponse{}, f.err
+	}
+	return openai.ChatCompletionResponse{
+		Model:   "fake-chat",
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: f.reply}}},
+		Usage:   openai.Usage{PromptTokens: 10, CompletionTokens: 2},
+	}, nil
+}
+
+func (f *fakeChat) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (services.ChatStream, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.last = req.Messages
+	f.streamCtx = ctx
+	if f.err != nil {
+		return nil, f.err
+	}
+	chunks := f.chunks
+	if chunks == nil {
+		chunks = []string{f.reply}
+	}
+	return &fakeStream{ctx: ctx, chunks: chunks, fail: f.streamErr, hold: f.hold}, nil
+}
+
+// upstream returns the context the last stream was opened with.
+func (f *fakeChat) upstream() context.Context {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.streamCtx
+}
+
+type fakeStream struct {
+	ctx       context.Context
+	chunks    []string
+	fail      error
+	hold      chan struct{}
+	usageSent bool
+}
+
+func (s *fakeStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	var resp openai.ChatCompletionStreamResponse
+	if s.hold != nil && len(s.chunks) > 0 {
+		select {
+		case <-s.hold:
+		case <-s.ctx.Done():
+		}
+	}
+	if err := s.ctx.Err(); err != nil {
+		return resp, err
+	}
+	if len(s.chunks) > 0 {
+		choice := openai.ChatCompletionStreamChoice{Delta: openai.ChatCompletionStreamChoiceDelta{Content: s.chunks[0]}}
+		s.chunks = s.chunks[1:]
+		if len(s.chunks) == 0 && s.fail == nil {
+			choice.FinishReason = openai.FinishReasonStop
+		}
+		resp.Choices = []openai.ChatCompletionStreamChoice{choice}
+		return resp, nil
+	}
+	if s.fail != nil {
+		return resp, s.fail
+	}
+	if !s.usageSent {
+		s.usageSent = true
+		resp.Model = "fake-chat"
+		resp.Usage = &openai.Usage{PromptTokens: 10, CompletionTokens: 2}
+		return resp, nil
+	}
+	return resp, io.EOF
+}
+
+func (s *fakeStream) Close() error { return nil }
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func newTestServer(t *testing.T, env, reply string) (http.Handler, *store.Memory, *fakeChat) {
+	cfg := config.Default()
+	cfg.Environment = env
+	chat := &fakeChat{reply: reply}
+	h, mem := newTestServerWith(t, cfg, chat)
+	return h, mem, chat
+}
+
+// newTestServerWith serves cfg over an in-memory store and chat.
+func newTestServerWith(t *testing.T, cfg *config.Config, chat *fakeChat) (http.Handler, *store.Memory) {
+	cfg.RateLimit.Enabled = false
+	space, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	mem := store.NewMemory()
+	server, err := api.NewServer(api.Deps{
+		Config:    cfg,
+		Documents: mem,
+		Queries:   mem,
+		Embedder:  fakeEmbedder{},
+		Search:    services.NewSearchService(mem, fakeEmbedder{}, space, cfg.Retrieval),
+		Chat:      chat,
+		Clock:     fixedClock{time.Unix(1700000000, 0)},
+		Reindex:   mem,
+	})
+	require.NoError(t, err)
+	return server.Handler(), mem
+}
+
+func doJSON(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestServersAreIndependent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	a, repoA, chatA := newTestServer(t, "staging", "from a")
+	b, repoB, _ := newTestServer(t, "development", "from b")
+
+	w := doJSON(a, "GET", "/health", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"healthy","timestamp":1700000000,"environment":"staging"}`, w.Body.String())
+	assert.Contains(t, doJSON(b, "GET", "/health", "").Body.String(), `"development"`)
+
+	w = doJSON(a, "POST", "/api/documents", `{"title":"Buttons","content":"Use the primary variant.","url":"https://docs/buttons"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	_, total, _ := repoA.ListDocuments(ctx, 0, 10)
+	assert.Equal(t, int64(1), total)
+	_, total, _ = repoB.ListDocuments(ctx, 0, 10)
+	assert.Zero(t, total, "documents must not leak between servers")
+
+	w = doJSON(a, "POST", "/api/chat", `{"messages":[{"role":"user","content":"Which button?"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "from a", resp.Message)
+	require.Len(t, chatA.last, 2)
+	assert.Contains(t, chatA.last[0].Content, "Buttons", "retrieved documents are passed as context")
+
+	queries, _ := repoA.RecentQueries(ctx, 10)
+	require.Len(t, queries, 1)
+	assert.Equal(t, []string{"https://docs/buttons"}, []string(queries[0].Sources))
+	usage, _ := repoA.UsageReport(ctx, "user", time.Time{}, time.Now().Add(time.Hour))
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(2), usage[0].Requests, "document embedding and chat")
+	queries, _ = repoB.RecentQueries(ctx, 10)
+	assert.Empty(t, queries)
+
+	assert.Equal(t, http.StatusNotFound, doJSON(b, "DELETE", "/api/documents/1", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(b, "DELETE", "/api/documents/abc", "").Code)
+	assert.Equal(t, http.StatusOK, doJSON(a, "DELETE", "/api/documents/1", "").Code)
+}
+
+func TestNewServerRequiresDeps(t *testing.T) {
+	_, err := api.NewServer(api.Deps{Config: config.Default()})
+	assert.Error(t, err)
+}
+
+func TestChatStopsWhenClientDisconnects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mem, chat := newTestServer(t, "test", "unused")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/chat", strings.NewReader(`{"messages":[{"role":"user","content":"hello?"}]}`)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, apierror.StatusClientClosedRequest, w.Code)
+	assert.NotNil(t, chat.last, "the provider saw the request's (cancelled) context")
+	usage, _ := mem.UsageReport(context.Background(), "user", time.Time{}, time.Now().Add(time.Hour))
+	require.Len(t, usage, 1, "usage is still recorded for a disconnected client")
+}
+
+func TestServerRunStopsOnCancel(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Port = "0"
+	cfg.Server.ShutdownTimeoutSeconds = 1
+	mem := store.NewMemory()
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: mem, Queries: mem, Embedder: fakeEmbedder{},
+		Search: services.NewSearchService(mem, fakeEmbedder{}, models.EmbeddingSpace{Model: "m", Dimensions: 2}, cfg.Retrieval),
+		Chat:   &fakeChat{},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
@@ -0,0 +1,225 @@
+// backend/tests/compat_test.go
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// newCompatClient points an unmodified OpenAI client at a test server.
+func newCompatClient(t *testing.T, chat *fakeChat) (*openai.Client, *store.Memory) {
+	oc, mem, _ := newCompatServer(t, chat)
+	return oc("sk-test"), mem
+}
+
+// newCompatServer returns a way to build OpenAI clients with a given key
+// for one test server.
+func newCompatServer(t *testing.T, chat *fakeChat) (func(key string) *openai.Client, *store.Memory, string) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.Auth.JWTSecret = wsSecret
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{ID: "tools", Key: "sk-test"}}
+	h, mem := newTestServerWith(t, cfg, chat)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	w := doJSON(h, "POST", "/api/v2/documents", `{"title":"Buttons","content":"Use the primary variant.","url":"https://docs/buttons"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	return func(key string) *openai.Client {
+		oc := openai.DefaultConfig(key)
+		oc.BaseURL = srv.URL + "/v1"
+		return openai.NewClientWithConfig(oc)
+	}, mem, srv.URL
+}
+
+var compatQuestion = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Which button?"}}
+
+func TestCompatChatCompletion(t *testing.T) {
+	chat := &fakeChat{reply: "The primary one."}
+	oc, mem := newCompatClient(t, chat)
+	ctx := context.Background()
+
+	resp, err := oc.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Messages: compatQuestion, Temperature: 0.2})
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "The primary one.", resp.Choices[0].Message.Content)
+	assert.Equal(t, 10, resp.Usage.PromptTokens)
+
+	require.Len(t, chat.last, 2)
+	assert.Equal(t, openai.ChatMessageRoleSystem, chat.last[0].Role)
+	assert.Contains(t, chat.last[0].Content, "Buttons", "the answer is grounded in the documentation")
+	assert.Equal(t, compatQuestion[0], chat.last[1])
+
+	queries, _ := mem.RecentQueries(ctx, 10)
+	require.Len(t, queries, 1)
+	assert.Equal(t, []string{"https://docs/buttons"}, []string(queries[0].Sources))
+	usage, _ := mem.UsageReport(ctx, "api_key", time.Time{}, time.Now().Add(time.Hour))
+	var principals []string
+	for _, u := range usage {
+		principals = append(principals, u.Principal)
+	}
+	assert.Contains(t, principals, "tools", "the bearer key identifies the caller")
+}
+
+func TestCompatChatCompletionStream(t *testing.T) {
+	chat := &fakeChat{chunks: []string{"The ", "primary ", "one."}}
+	oc, _ := newCompatClient(t, chat)
+
+	for _, includeUsage := range []bool{false, true} {
+		req := openai.ChatCompletionRequest{Messages: compatQuestion, Stream: true}
+		if includeUsage {
+			req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+		}
+		stream, err := oc.CreateChatCompletionStream(context.Background(), req)
+		require.NoError(t, err)
+		var answer strings.Builder
+		var usage *openai.Usage
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			if len(chunk.Choices) > 0 {
+				answer.WriteString(chunk.Choices[0].Delta.Content)
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+		}
+		stream.Close()
+		assert.Equal(t, "The primary one.", answer.String())
+		assert.Equal(t, includeUsage, usage != nil, "usage is only sent when asked for")
+	}
+}
+
+func TestCompatEmbeddings(t *testing.T) {
+	oc, _ := newCompatClient(t, &fakeChat{})
+	ctx := context.Background()
+
+	resp, err := oc.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{Input: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, 1, resp.Data[1].Index)
+	assert.Len(t, resp.Data[0].Embedding, 1536, "the configured embedding space")
+	assert.Equal(t, float32(1), resp.Data[0].Embedding[0])
+	assert.Equal(t, 2, resp.Usage.PromptTokens)
+
+	encoded, err := oc.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input:          []string{"a"},
+		Model:          openai.AdaEmbeddingV2,
+		EncodingFormat: openai.EmbeddingEncodingFormatBase64,
+	})
+	require.NoError(t, err)
+	require.Len(t, encoded.Data, 1)
+	assert.Len(t, encoded.Data[0].Embedding, 1536)
+	assert.Equal(t, float32(1), encoded.Data[0].Embedding[0])
+
+	_, err = oc.CreateEmbeddings(ctx, openai.EmbeddingRequestTokens{Input: [][]int{{1, 2}}})
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatusCode)
+
+	_, err = oc.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{Input: []string{"a"}, Dimensions: 256})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatusCode, "only the configured size")
+}
+
+func TestCompatRefusesOtherModels(t *testing.T) {
+	chat := &fakeChat{reply: "unused"}
+	oc, mem := newCompatClient(t, chat)
+	ctx := context.Background()
+
+	var apiErr *openai.APIError
+	_, err := oc.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: openai.GPT4, Messages: compatQuestion})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.HTTPStatusCode)
+	assert.Equal(t, "model_not_found", apiErr.Code)
+	assert.Equal(t, "invalid_request_error", apiErr.Type)
+	_, err = oc.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{Model: "gpt-4o-2024-08-06", Messages: compatQuestion, Stream: true})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "model_not_found", apiErr.Code)
+	assert.Nil(t, chat.last, "the provider is never called")
+
+	_, err = oc.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{Input: []string{"a"}, Model: openai.LargeEmbedding3})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "model_not_found", apiErr.Code)
+
+	resp, err := oc.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: openai.GPT3Dot5Turbo, Messages: compatQuestion})
+	require.NoError(t, err, "the configured model may be named")
+	assert.Equal(t, "unused", resp.Choices[0].Message.Content)
+
+	usage, _ := mem.UsageReport(ctx, "api_key", time.Time{}, time.Now().Add(time.Hour))
+	var requests int64
+	for _, u := range usage {
+		requests += u.Requests
+	}
+	assert.Equal(t, int64(2), requests, "the document upload and the one answered request")
+}
+
+func TestCompatRequiresKey(t *testing.T) {
+	chat := &fakeChat{reply: "Grounded."}
+	client, _, url := newCompatServer(t, chat)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "sk-guessed"} {
+		var apiErr *openai.APIError
+		_, err := client(key).CreateChatCompletion(ctx, openai.ChatCompletionRequest{Messages: compatQuestion})
+		require.ErrorAs(t, err, &apiErr, "key %q", key)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatusCode)
+		assert.Equal(t, "authentication_error", apiErr.Type)
+		_, err = client(key).CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{Input: []string{"a"}})
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatusCode)
+		_, err = client(key).ListModels(ctx)
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatusCode)
+	}
+	assert.Nil(t, chat.last)
+
+	resp, err := client(strings.TrimPrefix(bearer(t, jwt.MapClaims{"sub": "ada"}), "Bearer ")).
+		CreateChatCompletion(ctx, openai.ChatCompletionRequest{Messages: compatQuestion})
+	require.NoError(t, err, "a JWT works as the key too")
+	assert.Equal(t, "Grounded.", resp.Choices[0].Message.Content)
+
+	raw, err := http.Get(url + "/v1/models")
+	require.NoError(t, err)
+	defer raw.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, raw.StatusCode)
+	body, _ := io.ReadAll(raw.Body)
+	assert.JSONEq(t, `{"error":{"message":"An API key is required, as Authorization: Bearer <key>.","type":"authentication_error","param":null,"code":"unauthorized"}}`, string(body))
+}
+
+func TestCompatErrors(t *testing.T) {
+	oc, _ := newCompatClient(t, &fakeChat{err: apierror.New(apierror.CodeProviderRateLimit, "slow down")})
+	ctx := context.Background()
+
+	var apiErr *openai.APIError
+	_, err := oc.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Messages: compatQuestion})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatusCode)
+	assert.Equal(t, "rate_limit_error", apiErr.Type)
+	assert.Equal(t, string(apierror.CodeProviderRateLimit), apiErr.Code)
+
+	_, err = oc.CreateChatCompletion(ctx, openai.ChatCompletionRequest{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatusCode)
+	assert.Equal(t, "invalid_request_error", apiErr.Type)
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+)
+
+func TestConfigFileWithEnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: "9090"
+retrieval:
+  default_limit: 4
+`), 0o600))
+	t.Setenv("PORT", "9191")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "9191", cfg.Server.Port)
+	assert.Equal(t, 4, cfg.Retrieval.DefaultLimit)
+	assert.Equal(t, 10, cfg.Retrieval.MaxLimit)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("retreival:\n  default_limit: 4\n"), 0o600))
+
+	_, err := config.Load(path)
+	assert.Error(t, err)
+}
+
+func TestConfigValidateChunking(t *testing.T) {
+	cfg := config.Default()
+	cfg.Chunking.Size = 0
+	assert.ErrorContains(t, cfg.Validate(), "chunking.size")
+
+	cfg.Chunking = config.ChunkingConfig{Size: 100, Overlap: 100}
+	assert.ErrorContains(t, cfg.Validate(), "chunking.overlap")
+	cfg.Chunking.Overlap = 20
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidateProduction(t *testing.T) {
+	cfg := config.Default()
+	cfg.Environment = "production"
+	cfg.Auth.JWTSecret = "your-secret-key"
+	cfg.Database.URL = "postgres://user:password@db:5432/docs"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt_secret")
+	assert.Contains(t, err.Error(), "api_key")
+	assert.Contains(t, err.Error(), "password")
+	assert.Contains(t, err.Error(), "cors_origins")
+
+	cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Providers.OpenAI.APIKey = "sk-test"
+	cfg.Database.URL = "postgres://user:s3cr3t-value@db:5432/docs"
+	cfg.Server.CORSOrigins = []string{"https://docs.example.com"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{ID: "bot", Key: "short"}}
+	assert.ErrorContains(t, cfg.Validate(), "api_keys")
+}
+
+func TestConfigAPIKeys(t *testing.T) {
+	t.Setenv("API_KEYS", "bot=key-one, ci=key-two")
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Equal(t, []config.APIKeyConfig{{ID: "bot", Key: "key-one"}, {ID: "ci", Key: "key-two"}}, cfg.Auth.APIKeys)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Auth.APIKeys = append(cfg.Auth.APIKeys, config.APIKeyConfig{ID: "bot", Key: "key-one"}, config.APIKeyConfig{ID: "empty"})
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `id "bot" is used twice`)
+	assert.Contains(t, err.Error(), "needs an id and a key")
+
+	t.Setenv("API_KEYS", "no-key")
+	_, err = config.Load("")
+	assert.Error(t, err)
+}
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := config.Default()
+	cfg.Providers.OpenAI.APIKey = "sk-live-123"
+	cfg.Auth.JWTSecret = "super-secret"
+	cfg.Database.URL = "postgres://user:hunter2@db:5432/docs"
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{ID: "bot", Key: "team-key-value"}}
+
+	out, err := cfg.Redacted().YAML()
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "sk-live-123")
+	assert.NotContains(t, string(out), "super-secret")
+	assert.NotContains(t, string(out), "hunter2")
+	assert.NotContains(t, string(out), "team-key-value")
+	assert.Contains(t, string(out), "bot")
+	assert.Equal(t, "sk-live-123", cfg.Providers.OpenAI.APIKey, "original must be untouched")
+	assert.Equal(t, "team-key-value", cfg.Auth.APIKeys[0].Key)
+}
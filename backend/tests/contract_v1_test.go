@@ -0,0 +1,104 @@
+// backend/tests/contract_v1_test.go
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonKeys returns the sorted keys of the JSON object in body.
+func jsonKeys(t *testing.T, body []byte) []string {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &obj), string(body))
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// field returns obj[key] as raw JSON.
+func field(t *testing.T, body []byte, key string) []byte {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &obj), string(body))
+	return obj[key]
+}
+
+// first returns the first element of a JSON array.
+func first(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var arr []json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &arr), string(body))
+	require.NotEmpty(t, arr)
+	return arr[0]
+}
+
+var documentKeys = []string{"category", "content", "created_at", "id", "tags", "title", "updated_at", "url"}
+
+// The v1 response shapes are frozen; these tests pin them for both the
+// /api/v1 and unversioned mounts.
+func TestV1Contract(t *testing.T) {
+	for _, prefix := range []string{"/api/v1", "/api"} {
+		t.Run(prefix, func(t *testing.T) {
+			h, _, _ := newTestServer(t, "test", "Use the reset link.")
+
+			w := doJSON(h, http.MethodPost, prefix+"/documents", `{"title":"Passwords","content":"How to reset a password","url":"https://docs/pw","tags":["auth"]}`)
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, documentKeys, jsonKeys(t, w.Body.Bytes()))
+
+			w = doJSON(h, http.MethodGet, prefix+"/documents?limit=5", "")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []string{"documents", "limit", "page", "total"}, jsonKeys(t, w.Body.Bytes()))
+
+			w = doJSON(h, http.MethodPost, prefix+"/search", `{"query":"reset password"}`)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []string{"count", "query", "results"}, jsonKeys(t, w.Body.Bytes()))
+			result := first(t, field(t, w.Body.Bytes(), "results"))
+			assert.Equal(t, []string{"document", "score"}, jsonKeys(t, result))
+			assert.Equal(t, documentKeys, jsonKeys(t, field(t, result, "document")))
+
+			w = doJSON(h, http.MethodPost, prefix+"/chat", `{"messages":[{"role":"user","content":"reset password?"}],"stream":false}`)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []string{"cached", "message"}, jsonKeys(t, w.Body.Bytes()))
+			assert.JSONEq(t, `"Use the reset link."`, string(field(t, w.Body.Bytes(), "message")))
+
+			// v1 never validated individual messages.
+			w = doJSON(h, http.MethodPost, prefix+"/chat", `{"messages":[{"role":"robot","content":"hi"}]}`)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			w = doJSON(h, http.MethodDelete, prefix+"/documents/1", "")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"message":"Document deleted"}`, w.Body.String())
+		})
+	}
+}
+
+func TestV1IsDeprecated(t *testing.T) {
+	h, _, _ := newTestServer(t, "test", "")
+
+	for _, path := range []string{"/api/v1/documents", "/api/documents"} {
+		w := doJSON(h, http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Deprecation"), "@"), path)
+		assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"), path)
+		assert.Equal(t, `</api/v2/documents>; rel="successor-version"`, w.Header().Get("Link"), path)
+	}
+
+	// Errors from legacy routes are marked too.
+	w := doJSON(h, http.MethodDelete, "/api/v1/documents/42", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+
+	w = doJSON(h, http.MethodGet, "/api/v2/documents", "")
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
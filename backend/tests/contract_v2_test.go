@@ -0,0 +1,62 @@
+// backend/tests/contract_v2_test.go
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+)
+
+// TestV2Contract pins the /api/v2 response shapes. Changing one is a
+// breaking change and needs a new version.
+func TestV2Contract(t *testing.T) {
+	h, _, _ := newTestServer(t, "test", "Use the reset link.")
+
+	w := doJSON(h, http.MethodPost, "/api/v2/documents", `{"id":99,"title":"Passwords","content":"How to reset a password","url":"https://docs/pw","tags":["auth"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, documentKeys, jsonKeys(t, w.Body.Bytes()))
+	assert.JSONEq(t, `1`, string(field(t, w.Body.Bytes(), "id")), "clients cannot choose the ID")
+	assert.Equal(t, "/api/v2/documents/1", w.Header().Get("Location"))
+
+	w = doJSON(h, http.MethodPost, "/api/v2/documents", `{"content":"untitled"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeInvalidRequest, decodeProblem(t, w.Body.Bytes()).Code)
+
+	w = doJSON(h, http.MethodGet, "/api/v2/documents/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, documentKeys, jsonKeys(t, w.Body.Bytes()))
+	w = doJSON(h, http.MethodGet, "/api/v2/documents/2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(h, http.MethodGet, "/api/v2/documents?limit=1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"documents", "pagination"}, jsonKeys(t, w.Body.Bytes()))
+	assert.JSONEq(t, `{"page":1,"limit":1,"total":1,"has_more":false}`, string(field(t, w.Body.Bytes(), "pagination")))
+
+	w = doJSON(h, http.MethodPost, "/api/v2/search", `{"query":"reset password"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"query", "results"}, jsonKeys(t, w.Body.Bytes()))
+	// category is omitted when empty, as url would be.
+	hitKeys := []string{"id", "score", "snippet", "tags", "title", "url"}
+	assert.Equal(t, hitKeys, jsonKeys(t, first(t, field(t, w.Body.Bytes(), "results"))))
+
+	w = doJSON(h, http.MethodPost, "/api/v2/chat", `{"messages":[{"role":"user","content":"reset password?"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"cached", "message", "sources", "usage"}, jsonKeys(t, w.Body.Bytes()))
+	assert.JSONEq(t, `{"role":"assistant","content":"Use the reset link."}`, string(field(t, w.Body.Bytes(), "message")))
+	assert.Equal(t, []string{"completion_tokens", "embedding_tokens", "prompt_tokens"}, jsonKeys(t, field(t, w.Body.Bytes(), "usage")))
+	assert.JSONEq(t, `1`, string(field(t, first(t, field(t, w.Body.Bytes(), "sources")), "id")))
+
+	// v2 validates every message.
+	w = doJSON(h, http.MethodPost, "/api/v2/chat", `{"messages":[{"role":"robot","content":"hi"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(h, http.MethodDelete, "/api/v2/documents/1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	w = doJSON(h, http.MethodDelete, "/api/v2/documents/1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
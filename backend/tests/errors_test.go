@@ -0,0 +1,95 @@
+// backend/tests/errors_test.go
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+)
+
+func TestProviderErrorMapping(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   apierror.Code
+		status int
+	}{
+		{&openai.APIError{HTTPStatusCode: 429, Code: "rate_limit_exceeded"}, apierror.CodeProviderRateLimit, 429},
+		{&openai.APIError{HTTPStatusCode: 429, Code: "insufficient_quota"}, apierror.CodeProviderQuota, 503},
+		{&openai.APIError{HTTPStatusCode: 400, Code: "context_length_exceeded"}, apierror.CodeContextTooLong, 413},
+		{&openai.APIError{HTTPStatusCode: 400, Code: "content_policy_violation"}, apierror.CodeContentFiltered, 422},
+		{&openai.APIError{HTTPStatusCode: 401, Type: "invalid_request_error"}, apierror.CodeProviderAuth, 502},
+		{&openai.APIError{HTTPStatusCode: 400, Type: "invalid_request_error"}, apierror.CodeProviderError, 502},
+		{&openai.RequestError{HTTPStatusCode: 503}, apierror.CodeProviderUnavailable, 502},
+		{fmt.Errorf("embed query: %w", &openai.APIError{HTTPStatusCode: 429}), apierror.CodeProviderRateLimit, 429},
+		{context.Canceled, apierror.CodeRequestCancelled, apierror.StatusClientClosedRequest},
+		{context.DeadlineExceeded, apierror.CodeTimeout, 504},
+		{fmt.Errorf("boom"), apierror.CodeInternal, 500},
+	}
+	for _, tc := range cases {
+		e := apierror.From(tc.err)
+		assert.Equal(t, tc.code, e.Code, "%v", tc.err)
+		assert.Equal(t, tc.status, e.Status, "%v", tc.err)
+	}
+	assert.Equal(t, apierror.CodeDatabaseError, apierror.Database(fmt.Errorf("conn refused"), "x").Code)
+	assert.Equal(t, apierror.CodeRequestCancelled, apierror.Database(context.Canceled, "x").Code)
+}
+
+func decodeProblem(t *testing.T, body []byte) apierror.Problem {
+	var p apierror.Problem
+	require.NoError(t, json.Unmarshal(body, &p), string(body))
+	return p
+}
+
+func TestProblemResponses(t *testing.T) {
+	h, _, chat := newTestServer(t, "test", "")
+
+	w := doJSON(h, http.MethodPost, "/api/search", `{"query":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.ContentType, w.Header().Get("Content-Type"))
+	p := decodeProblem(t, w.Body.Bytes())
+	assert.Equal(t, apierror.CodeInvalidRequest, p.Code)
+	assert.Equal(t, "/problems/invalid_request", p.Type)
+	assert.Equal(t, "/api/search", p.Instance)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), p.RequestID)
+
+	w = doJSON(h, http.MethodGet, "/api/v2/documents?limit=abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(h, http.MethodGet, "/api/v2/documents?limit=1000", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(h, http.MethodGet, "/api/v2/documents?page=x", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// v1 is frozen: bad paging falls back to the defaults and clamps.
+	w = doJSON(h, http.MethodGet, "/api/documents?limit=abc&page=x", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `1`, string(field(t, w.Body.Bytes(), "page")))
+	assert.JSONEq(t, `10`, string(field(t, w.Body.Bytes(), "limit")))
+	w = doJSON(h, http.MethodGet, "/api/documents?limit=1000&page=0", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `1`, string(field(t, w.Body.Bytes(), "page")))
+	assert.JSONEq(t, `100`, string(field(t, w.Body.Bytes(), "limit")))
+
+	w = doJSON(h, http.MethodGet, "/api/nope", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apierror.CodeNotFound, decodeProblem(t, w.Body.Bytes()).Code)
+	w = doJSON(h, http.MethodPut, "/api/search", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	chat.err = &openai.APIError{HTTPStatusCode: 429, Message: "Rate limit reached for org-secret"}
+	w = doJSON(h, http.MethodPost, "/api/chat", `{"messages":[{"role":"user","content":"hi"}]}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, apierror.CodeProviderRateLimit, decodeProblem(t, w.Body.Bytes()).Code)
+	assert.NotContains(t, w.Body.String(), "org-secret", "provider messages are not passed through")
+
+	chat.err = &openai.APIError{HTTPStatusCode: 400, Code: "context_length_exceeded", Message: "too long"}
+	w = doJSON(h, http.MethodPost, "/api/chat", `{"messages":[{"role":"user","content":"hi"}]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/health"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var dbCalls int32
+	dbErr := error(nil)
+	monitor := health.NewMonitor(
+		health.Check{Name: "database", Critical: true, Timeout: time.Second, TTL: 5 * time.Second,
+			Func: func(context.Context) error { atomic.AddInt32(&dbCalls, 1); return dbErr }},
+		health.Check{Name: "chat_provider", Timeout: 10 * time.Millisecond, TTL: time.Minute,
+			Func: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+	)
+	monitor.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	report := monitor.Run(ctx)
+	assert.Equal(t, health.StatusDegraded, report.Status, "a slow provider only degrades")
+	assert.True(t, report.Ready())
+	assert.Equal(t, health.StatusOK, report.Components["database"].Status)
+	assert.Contains(t, report.Components["chat_provider"].Error, "deadline exceeded")
+
+	dbErr = errors.New("connection refused")
+	report = monitor.Run(ctx)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dbCalls), "results are cached for the TTL")
+	assert.True(t, report.Ready())
+
+	now = now.Add(6 * time.Second)
+	report = monitor.Run(ctx)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dbCalls))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.False(t, report.Ready())
+	assert.Equal(t, "connection refused", report.Components["database"].Error)
+}
+
+func TestReadinessEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	mem := store.NewMemory()
+	failing := errors.New("schema is at version 6, this build expects 7")
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: mem, Queries: mem, Embedder: fakeEmbedder{},
+		Search: services.NewSearchService(mem, fakeEmbedder{}, models.EmbeddingSpace{Model: "m", Dimensions: 2}, cfg.Retrieval),
+		Chat:   &fakeChat{},
+		Health: health.NewMonitor(health.Check{Name: "migrations", Critical: true, Timeout: time.Second,
+			Func: func(context.Context) error { return failing }}),
+	})
+	require.NoError(t, err)
+
+	w := doJSON(server.Handler(), "GET", "/livez", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(server.Handler(), "GET", "/readyz", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, failing.Error(), report.Components["migrations"].Error)
+}
@@ -0,0 +1,116 @@
+// backend/tests/logging_test.go
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/logging"
+)
+
+// captureLogs points the logging package at a buffer for the rest of the
+// test.
+func captureLogs(t *testing.T, cfg config.LoggingConfig) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	logging.Setup(cfg, &buf)
+	t.Cleanup(func() {
+		logging.Setup(config.Default().Logging, os.Stderr)
+		slog.SetDefault(previous)
+	})
+	return &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var r map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r), scanner.Text())
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	buf := captureLogs(t, config.Default().Logging)
+	h, _, _ := newTestServer(t, "test", "")
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+
+	w = doJSON(h, http.MethodGet, "/health", "")
+	generated := w.Header().Get("X-Request-ID")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), generated)
+
+	req = httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("X-Request-ID", "not a token\n")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.NotEqual(t, "not a token\n", w.Header().Get("X-Request-ID"), "unsafe IDs are replaced")
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 3)
+	assert.Equal(t, "http", records[0]["component"])
+	assert.Equal(t, "abc-123", records[0]["request_id"])
+	assert.Equal(t, "/health", records[0]["route"])
+	assert.EqualValues(t, 200, records[0]["status"])
+	assert.Equal(t, generated, records[1]["request_id"])
+}
+
+func TestComponentLogLevels(t *testing.T) {
+	cfg := config.Default().Logging
+	cfg.Level = "debug"
+	cfg.Levels = map[string]string{"http": "warn"}
+	buf := captureLogs(t, cfg)
+
+	logging.For("http").Info("hidden")
+	logging.For("http").Warn("shown")
+	logging.For("api").Debug("shown")
+	// Levels replaces the default db=warn, so db follows the global level.
+	logging.For("db").Debug("shown")
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 3)
+	for _, r := range records {
+		assert.Equal(t, "shown", r["msg"])
+	}
+}
+
+func TestLogRedaction(t *testing.T) {
+	cfg := config.Default().Logging
+	cfg.Level = "debug"
+	cfg.Redact = true
+	buf := captureLogs(t, cfg)
+	h, _, _ := newTestServer(t, "test", "I will write to jane@example.com.")
+
+	w := doJSON(h, http.MethodPost, "/api/chat", `{"messages":[{"role":"user","content":"Email jane@example.com about tokens"}]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	doJSON(h, http.MethodGet, "/health?user=jane@example.com", "")
+
+	out := buf.String()
+	assert.NotContains(t, out, "jane@example.com")
+	assert.NotContains(t, out, "about tokens")
+	var answered map[string]any
+	for _, r := range logRecords(t, buf) {
+		if r["msg"] == "query answered" {
+			answered = r
+		}
+	}
+	require.NotNil(t, answered)
+	assert.Equal(t, logging.Redacted, answered["query"])
+	assert.Equal(t, logging.Redacted, answered["response"])
+}
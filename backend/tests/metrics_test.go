@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/metrics"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	space, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	mem := store.NewMemory()
+	m := metrics.New()
+	embedder := m.Embedder(fakeEmbedder{})
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: mem, Queries: mem, Embedder: embedder,
+		Search:  m.Searcher(services.NewSearchService(mem, embedder, space, cfg.Retrieval)),
+		Chat:    m.ChatProvider(&fakeChat{reply: "hi"}),
+		Metrics: m,
+	})
+	require.NoError(t, err)
+	h := server.Handler()
+
+	require.Equal(t, http.StatusCreated, doJSON(h, "POST", "/api/documents", `{"title":"A","content":"alpha"}`).Code)
+	require.Equal(t, http.StatusOK, doJSON(h, "POST", "/api/search", `{"query":"alpha"}`).Code)
+	require.Equal(t, http.StatusOK, doJSON(h, "POST", "/api/chat", `{"messages":[{"role":"user","content":"alpha?"}]}`).Code)
+	doJSON(h, "DELETE", "/api/documents/7", "")
+
+	assert.Equal(t, float64(10), testutil.ToFloat64(m.TokensTotal.WithLabelValues(cfg.Providers.OpenAI.ChatModel, metrics.TokensPrompt)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.TokensTotal.WithLabelValues(space.Model, metrics.TokensEmbedding)),
+		"one document and two queries")
+
+	w := doJSON(h, "GET", "/metrics", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `docs_assistant_http_request_duration_seconds_count{method="POST",route="/api/chat",status="200"} 1`)
+	assert.Contains(t, body, `docs_assistant_provider_request_duration_seconds_count{model="`+cfg.Providers.OpenAI.ChatModel+`",operation="chat",provider="openai"} 1`)
+	assert.Contains(t, body, `docs_assistant_http_request_duration_seconds_count{method="DELETE",route="/api/documents/:id",status="404"} 1`)
+	assert.Contains(t, body, "docs_assistant_retrieval_results_count 2", "search and chat each retrieve once")
+	assert.Contains(t, body, "docs_assistant_retrieval_score_bucket")
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/migrate"
+	"github.com/yourname/ai-documentation-assistant/migrations"
+)
+
+func TestSplitStatementsDollarQuoting(t *testing.T) {
+	script := `
+-- leading comment; with a semicolon
+CREATE TABLE t (id INT, note TEXT DEFAULT 'a;b');
+
+CREATE OR REPLACE FUNCTION touch()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DO $body$ BEGIN PERFORM 1; END $body$;
+/* block; comment /* nested; */ still comment */
+SELECT $1::int;
+-- trailing comment only
+`
+	stmts := migrate.SplitStatements(script)
+	require.Len(t, stmts, 4)
+	assert.Contains(t, stmts[0], "'a;b'")
+	assert.Contains(t, stmts[1], "RETURN NEW;\nEND;\n$$ language 'plpgsql';")
+	assert.Contains(t, stmts[2], "PERFORM 1; END $body$;")
+	assert.Contains(t, stmts[3], "SELECT $1::int;")
+}
+
+func TestLoadMigrationsOrdersAndPairs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.up.sql":    {Data: []byte("SELECT 10;")},
+		"002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"002_second.down.sql": {Data: []byte("SELECT -2;")},
+		"README.md":           {Data: []byte("ignored")},
+	}
+	all, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, int64(2), all[0].Version)
+	assert.Equal(t, "SELECT -2;", all[0].Down)
+	assert.Equal(t, int64(10), all[1].Version)
+	assert.Empty(t, all[1].Down)
+
+	_, err = migrate.Load(fstest.MapFS{"003_orphan.down.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(t, err)
+}
+
+func TestNoTransactionDirective(t *testing.T) {
+	assert.True(t, migrate.NoTransaction("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY i ON t (c);"))
+	assert.True(t, migrate.NoTransaction("\n  -- migrate:no-transaction\n"))
+	assert.False(t, migrate.NoTransaction("CREATE TABLE t (c int);\n-- migrate:no-transaction"))
+}
+
+func TestEmbeddedMigrationsAreReversible(t *testing.T) {
+	all, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+	for i, m := range all {
+		assert.Equal(t, int64(i+1), m.Version, "migration versions should be contiguous")
+		assert.NotEmpty(t, m.Down, "migration %d_%s needs a down file", m.Version, m.Name)
+		assert.NotEmpty(t, migrate.SplitStatements(m.Up))
+	}
+}
@@ -0,0 +1,136 @@
+// backend/tests/openapi_test.go
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/client"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/openapi"
+)
+
+// Every versioned route is documented, every documented public route is
+// served, and /api serves the same routes as /api/v1.
+func TestSpecMatchesRoutes(t *testing.T) {
+	h, _, _ := newTestServer(t, "test", "")
+	engine, ok := h.(*gin.Engine)
+	require.True(t, ok)
+
+	documented := map[string]bool{}
+	for _, op := range api.Operations() {
+		documented[op.Method+" "+op.Path] = true
+	}
+	served := map[string]bool{}
+	for _, r := range engine.Routes() {
+		served[r.Method+" "+r.Path] = true
+	}
+
+	for key := range served {
+		method, path, _ := strings.Cut(key, " ")
+		switch {
+		case strings.HasPrefix(path, "/api/v1/"), strings.HasPrefix(path, "/api/v2/"), strings.HasPrefix(path, "/api/admin/"):
+			assert.True(t, documented[key], "%s is served but not documented", key)
+		case strings.HasPrefix(path, "/api/") && path != "/api/openapi.json" && path != "/api/docs" && path != "/api/ws":
+			assert.True(t, served[method+" /api/v1"+strings.TrimPrefix(path, "/api")], "%s has no /api/v1 twin", key)
+		}
+	}
+	for key := range documented {
+		if strings.Contains(key, "/api/admin/") {
+			continue // mounted only with a database
+		}
+		assert.True(t, served[key], "%s is documented but not served", key)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	h, _, _ := newTestServer(t, "test", "")
+
+	w := doJSON(h, http.MethodGet, "/api/openapi.json", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Contains(t, doc.Paths["/api/v1/documents/{id}"], "delete")
+	assert.Contains(t, doc.Paths["/api/admin/reindex/{id}"], "get")
+
+	search := doc.Components.Schemas["SearchRequest"]
+	require.NotNil(t, search)
+	assert.ElementsMatch(t, []any{"query"}, search["required"])
+	var searchOp struct{ Description string }
+	require.NoError(t, json.Unmarshal(doc.Paths["/api/v2/search"]["post"], &searchOp))
+	assert.Contains(t, searchOp.Description, "retrieval.max_limit is refused")
+	w = doJSON(h, http.MethodPost, "/api/v2/search", `{"query":"buttons","limit":11}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "as documented, limits over max_limit are not lowered")
+	assert.NotContains(t, doc.Components.Schemas["Document"]["properties"], "Embedding")
+	assert.Contains(t, doc.Components.Schemas, "Problem")
+
+	// Every $ref resolves.
+	for _, ref := range strings.Split(w.Body.String(), `"$ref": "#/components/schemas/`)[1:] {
+		name, _, _ := strings.Cut(ref, `"`)
+		assert.Contains(t, doc.Components.Schemas, name)
+	}
+
+	w = doJSON(h, http.MethodGet, "/api/docs", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/api/openapi.json")
+}
+
+// The committed client is what the generator produces for the current
+// routes; run `go generate ./client` when this fails.
+func TestGeneratedClientIsCurrent(t *testing.T) {
+	want, err := openapi.GenerateClient("client", "cmd/apigen", apierror.Problem{}, api.Operations())
+	require.NoError(t, err)
+	got, err := os.ReadFile("../client/client_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "client/client_gen.go is stale")
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	h, _, _ := newTestServer(t, "test", "Use the reset link.")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	c := client.New(srv.URL)
+	ctx := context.Background()
+
+	doc, err := c.CreateDocumentV2(ctx, client.DocumentInput{Title: "Passwords", Content: "How to reset a password", Tags: []string{"auth"}})
+	require.NoError(t, err)
+	assert.NotZero(t, doc.ID)
+
+	list, err := c.ListDocumentsV2(ctx, &client.ListDocumentsV2Params{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Pagination.Total)
+	assert.Equal(t, 5, list.Pagination.Limit)
+
+	chat, err := c.ChatV2(ctx, client.ChatRequestV2{Messages: []client.Message{{Role: "user", Content: "reset password?"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "Use the reset link.", chat.Message.Content)
+	require.Len(t, chat.Sources, 1)
+
+	_, err = c.SearchV2(ctx, client.SearchRequest{Query: "x"})
+	var problem *client.Error
+	require.True(t, errors.As(err, &problem), "%v", err)
+	assert.Equal(t, string(apierror.CodeInvalidRequest), problem.Code)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+
+	require.NoError(t, c.DeleteDocumentV2(ctx, uint64(doc.ID)))
+	_, err = c.GetDocumentV2(ctx, uint64(doc.ID))
+	require.True(t, errors.As(err, &problem), "%v", err)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+}
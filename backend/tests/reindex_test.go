@@ -0,0 +1,221 @@
+// backend/tests/reindex_test.go
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+// recordingEmbedder is fakeEmbedder that remembers what it embedded.
+type recordingEmbedder struct {
+	fakeEmbedder
+	texts []string
+}
+
+func (e *recordingEmbedder) Embed(ctx context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	e.texts = append(e.texts, texts...)
+	return e.fakeEmbedder.Embed(ctx, space, texts)
+}
+
+// newReindexCorpus stores n documents with vectors in old only.
+func newReindexCorpus(t *testing.T, n int, old models.EmbeddingSpace) *store.Memory {
+	mem := store.NewMemory()
+	for i := 1; i <= n; i++ {
+		doc := models.Document{Title: fmt.Sprintf("doc-%d", i), Content: fmt.Sprintf("doc-%d", i)}
+		vector := make(models.Vector, old.Dimensions)
+		vector[0] = 1
+		require.NoError(t, mem.CreateDocument(context.Background(), &doc, []models.Embedding{
+			{Model: old.Model, Dimensions: old.Dimensions, Vector: vector},
+		}))
+	}
+	return mem
+}
+
+func TestReindexerResumesFromCursor(t *testing.T) {
+	old := models.EmbeddingSpace{Model: "old-model", Dimensions: 2}
+	target := models.EmbeddingSpace{Model: "new-model", Dimensions: 3}
+	mem := newReindexCorpus(t, 5, old)
+	embedder := &recordingEmbedder{}
+
+	// Interrupt the run after its first batch.
+	ctx, cancel := context.WithCancel(context.Background())
+	reindexer := services.NewReindexer(mem, embedder, target, old)
+	reindexer.Progress = func(models.ReindexJob) { cancel() }
+	job, err := reindexer.Start(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, job.TotalDocuments)
+	require.ErrorIs(t, reindexer.Run(ctx, job), context.Canceled)
+
+	paused, err := mem.GetReindexJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReindexPaused, paused.Status)
+	assert.Equal(t, uint(2), paused.LastDocumentID)
+	assert.Equal(t, 2, paused.ProcessedDocuments)
+	assert.Equal(t, []string{"doc-1", "doc-2"}, embedder.texts)
+
+	// Starting again claims the same job and carries on where it stopped.
+	ctx = context.Background()
+	reindexer = services.NewReindexer(mem, embedder, target, old)
+	resumed, err := reindexer.Start(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, resumed.ID)
+	assert.Equal(t, models.ReindexRunning, resumed.Status)
+	assert.Equal(t, 2, resumed.BatchSize, "an unset batch size keeps the job's")
+	require.NoError(t, reindexer.Run(ctx, resumed))
+
+	assert.Equal(t, []string{"doc-1", "doc-2", "doc-3", "doc-4", "doc-5"}, embedder.texts, "no document is embedded twice")
+	done, err := mem.GetReindexJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReindexCompleted, done.Status)
+	assert.Equal(t, 5, done.ProcessedDocuments)
+	assert.Equal(t, 5, done.EmbeddingTokens)
+
+	jobs, err := mem.ListReindexJobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	_, err = reindexer.Start(ctx, 2)
+	assert.ErrorIs(t, err, services.ErrAlreadyActive)
+}
+
+func TestReindexerFlipsSearchAtTheEnd(t *testing.T) {
+	ctx := context.Background()
+	old := models.EmbeddingSpace{Model: "old-model", Dimensions: 2}
+	target := models.EmbeddingSpace{Model: "new-model", Dimensions: 3}
+	mem := newReindexCorpus(t, 5, old)
+	search := services.NewSearchService(mem, fakeEmbedder{}, old, config.Default().Retrieval)
+	searchSpace := func() (models.EmbeddingSpace, int) {
+		retrieval, err := search.Search(ctx, services.SearchQuery{Text: "anything", Limit: 10})
+		require.NoError(t, err)
+		return retrieval.Space, len(retrieval.Results)
+	}
+
+	reindexer := services.NewReindexer(mem, fakeEmbedder{}, target, old)
+	var batches int
+	reindexer.Progress = func(job models.ReindexJob) {
+		space, found := searchSpace()
+		if job.Status == models.ReindexCompleted {
+			assert.Equal(t, target, space, "search switches with the flip")
+			return
+		}
+		batches++
+		assert.Equal(t, old, space, "search stays on the old space while %d of 5 are done", job.ProcessedDocuments)
+		assert.Equal(t, 5, found, "and finds the whole corpus there")
+	}
+	job, err := reindexer.Start(ctx, 2)
+	require.NoError(t, err)
+	running, ok, err := mem.RunningReindexSpace(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, target, running)
+
+	require.NoError(t, reindexer.Run(ctx, job))
+	assert.Equal(t, 3, batches)
+	space, found := searchSpace()
+	assert.Equal(t, target, space)
+	assert.Equal(t, 5, found)
+	_, ok, err = mem.RunningReindexSpace(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	removed, err := mem.PruneEmbeddings(ctx, target)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), removed, "the old vectors can go once nothing searches them")
+}
+
+// A writer looks up the spaces, embeds, then stores; a re-index can start
+// or flip in between. CreateDocument must refuse rather than leave the
+// document out of the space search ends up on.
+func TestCreateDocumentRacesTheFlip(t *testing.T) {
+	ctx := context.Background()
+	old := models.EmbeddingSpace{Model: "old-model", Dimensions: 2}
+	target := models.EmbeddingSpace{Model: "new-model", Dimensions: 3}
+	mem := newReindexCorpus(t, 2, old)
+	oldOnly := []models.Embedding{{Model: old.Model, Dimensions: old.Dimensions, Vector: models.Vector{1, 0}}}
+
+	_, running, err := mem.RunningReindexSpace(ctx)
+	require.NoError(t, err)
+	require.False(t, running, "the writer sees no re-index and embeds in the old space only")
+
+	reindexer := services.NewReindexer(mem, fakeEmbedder{}, target, old)
+	job, err := reindexer.Start(ctx, 10)
+	require.NoError(t, err)
+	doc := models.Document{Title: "late", Content: "late"}
+	assert.ErrorIs(t, mem.CreateDocument(ctx, &doc, oldOnly), store.ErrSpaceChanged, "a re-index started meanwhile")
+	assert.Zero(t, doc.ID, "nothing is stored")
+
+	require.NoError(t, reindexer.Run(ctx, job))
+	active, err := mem.ActiveEmbeddingSpace(ctx, old)
+	require.NoError(t, err)
+	require.Equal(t, target, active)
+	assert.ErrorIs(t, mem.CreateDocument(ctx, &doc, oldOnly), store.ErrSpaceChanged, "the re-index flipped meanwhile")
+
+	require.NoError(t, mem.CreateDocument(ctx, &doc, []models.Embedding{
+		{Model: target.Model, Dimensions: target.Dimensions, Vector: models.Vector{1, 0, 0}},
+	}))
+	hits, err := mem.NearestDocuments(ctx, target, models.Vector{1, 0, 0}, 10, store.SearchTuning{})
+	require.NoError(t, err)
+	assert.Len(t, hits, 3)
+}
+
+// flippingEmbedder runs flip before its first embedding, as if a re-index
+// completed while the provider was answering.
+type flippingEmbedder struct {
+	fakeEmbedder
+	flip  func()
+	calls []models.EmbeddingSpace
+}
+
+func (e *flippingEmbedder) Embed(ctx context.Context, space models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	if e.flip != nil {
+		flip := e.flip
+		e.flip = nil
+		flip()
+	}
+	e.calls = append(e.calls, space)
+	return e.fakeEmbedder.Embed(ctx, space, texts)
+}
+
+func TestCreateDocumentReembedsAfterFlip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.RateLimit.Enabled = false
+	old, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	target := models.EmbeddingSpace{Model: "new-model", Dimensions: 3}
+	mem := newReindexCorpus(t, 1, old)
+	embedder := &flippingEmbedder{flip: func() {
+		reindexer := services.NewReindexer(mem, fakeEmbedder{}, target, old)
+		job, err := reindexer.Start(ctx, 10)
+		require.NoError(t, err)
+		require.NoError(t, reindexer.Run(ctx, job))
+	}}
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: mem, Queries: mem, Embedder: embedder,
+		Search:  services.NewSearchService(mem, embedder, old, cfg.Retrieval),
+		Chat:    &fakeChat{},
+		Clock:   fixedClock{time.Unix(1700000000, 0)},
+		Reindex: mem,
+	})
+	require.NoError(t, err)
+
+	w := doJSON(server.Handler(), http.MethodPost, "/api/documents", `{"title":"Late","content":"Added during the flip."}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, []models.EmbeddingSpace{old, target}, embedder.calls, "embedded again in the space search moved to")
+	hits, err := mem.NearestDocuments(ctx, target, models.Vector{1, 0, 0}, 10, store.SearchTuning{})
+	require.NoError(t, err)
+	assert.Len(t, hits, 2)
+}
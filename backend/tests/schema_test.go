@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/database"
+	"github.com/yourname/ai-documentation-assistant/internal/migrate"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+)
+
+func TestVectorRoundTrip(t *testing.T) {
+	v := models.Vector{0.5, -1, 0.25}
+	raw, err := v.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[0.5,-1,0.25]", raw)
+
+	var back models.Vector
+	require.NoError(t, back.Scan([]byte("[0.5, -1, 0.25]")))
+	assert.Equal(t, v, back)
+
+	assert.Error(t, back.Scan("0.5,1"))
+}
+
+func TestCanonicalType(t *testing.T) {
+	assert.Equal(t, "character varying(255)", migrate.CanonicalType("VARCHAR(255)"))
+	assert.Equal(t, "timestamp without time zone", migrate.CanonicalType("timestamp"))
+	assert.Equal(t, "text[]", migrate.CanonicalType("TEXT[]"))
+	assert.Equal(t, "numeric(12,6)", migrate.CanonicalType("NUMERIC(12, 6)"))
+	assert.Equal(t, "vector(1536)", migrate.CanonicalType("VECTOR(1536)"))
+}
+
+func TestSchemaDiff(t *testing.T) {
+	expected, err := migrate.ExpectedSchema(&models.Document{})
+	require.NoError(t, err)
+
+	// The documents table as created by 001_initial_schema.up.sql.
+	live := []migrate.Table{{Name: "documents", Columns: []migrate.Column{
+		{Name: "id", Type: "integer", NotNull: true},
+		{Name: "title", Type: "character varying(255)", NotNull: true},
+		{Name: "content", Type: "text", NotNull: true},
+		{Name: "url", Type: "character varying(500)"},
+		{Name: "category", Type: "character varying(100)"},
+		{Name: "tags", Type: "text[]"},
+		{Name: "created_at", Type: "timestamp without time zone"},
+		{Name: "updated_at", Type: "timestamp without time zone"},
+	}}}
+	assert.Empty(t, migrate.Diff(expected, live))
+
+	live[0].Columns[1].Type = "text"
+	live[0].Columns = append(live[0].Columns, migrate.Column{Name: "legacy", Type: "text"})
+	drifts := migrate.Diff(expected, live)
+	require.Len(t, drifts, 2)
+	assert.Equal(t, "title", drifts[0].Column)
+	assert.Equal(t, "legacy", drifts[1].Column)
+
+	assert.Len(t, migrate.Diff(expected, nil), 1)
+}
+
+func TestResolveEmbeddingSpace(t *testing.T) {
+	space, err := models.ResolveEmbeddingSpace("text-embedding-ada-002", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1536, space.Dimensions)
+	assert.Zero(t, space.RequestDimensions())
+
+	space, err = models.ResolveEmbeddingSpace("text-embedding-3-large", 256)
+	require.NoError(t, err)
+	assert.Equal(t, 256, space.RequestDimensions())
+
+	_, err = models.ResolveEmbeddingSpace("text-embedding-ada-002", 512)
+	assert.Error(t, err, "ada-002 cannot be shortened")
+	_, err = models.ResolveEmbeddingSpace("text-embedding-3-small", 4096)
+	assert.Error(t, err)
+	_, err = models.ResolveEmbeddingSpace("my-local-model", 0)
+	assert.Error(t, err, "unknown models must state their size")
+}
+
+func TestVectorIndexSpec(t *testing.T) {
+	space := models.EmbeddingSpace{Model: "text-embedding-3-small", Dimensions: 512}
+	spec := database.VectorIndexSpec{Space: space, Method: database.IndexHNSW, M: 16, EfConstruction: 64}
+	require.NoError(t, spec.Validate())
+	assert.Equal(t, "idx_embeddings_hnsw_text_embedding_3_small_512", spec.Name())
+	assert.Equal(t, "(e.vector::vector(512))", database.VectorExpr("e", space))
+	assert.Equal(t, "e.model = 'text-embedding-3-small' AND e.dimensions = 512", database.SpacePredicate("e", space))
+
+	spec.EfConstruction = 8
+	assert.Error(t, spec.Validate(), "ef_construction below 2*m")
+
+	wide := database.VectorIndexSpec{Space: models.EmbeddingSpace{Model: "text-embedding-3-large", Dimensions: 3072}, Method: database.IndexIVFFlat}
+	assert.Error(t, wide.Validate(), "pgvector cannot index more than 2000 dimensions")
+
+	_, err := models.ResolveEmbeddingSpace("custom'; DROP TABLE embeddings; --", 8)
+	assert.Error(t, err)
+}
@@ -1,18 +1,289 @@
 package tests
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
-	// TODO: Add test implementations
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
 )
 
+// fakeEmbeddings serves /v1/embeddings, encoding each input's number (the
+// text "doc-N") into the first vector component. The first failFirst calls
+// return 429.
+func fakeEmbeddings(t *testing.T, dims int, failFirst int32) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n <= failFirst {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"slow down","type":"rate_limit"}}`)
+			return
+		}
+		var req struct {
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		type item struct {
+			Object    string    `json:"object"`
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := make([]item, len(req.Input))
+		// Reply in reverse to check vectors are placed by index.
+		for i := range req.Input {
+			var num int
+			fmt.Sscanf(strings.TrimPrefix(req.Input[i], "doc-"), "%d", &num)
+			vec := make([]float32, dims)
+			vec[0] = float32(num)
+			data[len(req.Input)-1-i] = item{Object: "embedding", Index: i, Embedding: vec}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"object": "list",
+			"data":   data,
+			"usage":  map[string]int{"prompt_tokens": len(req.Input), "total_tokens": len(req.Input)},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
 func TestEmbeddingsService(t *testing.T) {
-	// TODO: Implement embeddings service test
+	srv, calls := fakeEmbeddings(t, 8, 1)
+	svc := services.NewEmbeddingService(config.OpenAIConfig{APIKey: "test", BaseURL: srv.URL + "/v1"},
+		models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 8})
+	svc.RetryBackoff = time.Millisecond
+	svc.BatchTokens = 10 // "doc-N" estimates at 2 tokens, so 5 inputs per request
+
+	texts := make([]string, 23)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("doc-%d", i)
+	}
+	vectors, tokens, err := svc.GetEmbeddings(context.Background(), texts)
+	require.NoError(t, err)
+	require.Len(t, vectors, len(texts))
+	for i, v := range vectors {
+		assert.Equal(t, float32(i), v[0], "vector %d out of order", i)
+	}
+	assert.Equal(t, len(texts), tokens)
+	assert.Equal(t, int32(5+1), atomic.LoadInt32(calls), "5 batches plus one rate-limited attempt")
+}
+
+func TestEmbeddingsServiceGivesUp(t *testing.T) {
+	srv, _ := fakeEmbeddings(t, 8, 100)
+	svc := services.NewEmbeddingService(config.OpenAIConfig{APIKey: "test", BaseURL: srv.URL + "/v1"},
+		models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 8})
+	svc.RetryBackoff = time.Millisecond
+	svc.MaxRetries = 2
+
+	_, _, err := svc.GetEmbeddings(context.Background(), []string{"doc-1", "doc-2"})
+	assert.Error(t, err)
+}
+
+func TestEmbeddingsSendTextAsGiven(t *testing.T) {
+	var sent [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		sent = append(sent, req.Input)
+		data := make([]map[string]interface{}, len(req.Input))
+		for i := range req.Input {
+			data[i] = map[string]interface{}{"object": "embedding", "index": i, "embedding": []float32{1, 0}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "data": data})
+	}))
+	t.Cleanup(srv.Close)
+	svc := services.NewEmbeddingService(config.OpenAIConfig{APIKey: "test", BaseURL: srv.URL + "/v1"},
+		models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 2})
+	svc.Cache = services.NewEmbeddingCache(nil, config.EmbeddingCacheConfig{Enabled: true, MemoryEntries: 10})
+
+	code := "func main() {\n\tfmt.Println(\"hi\")\n}"
+	vectors, _, err := svc.GetEmbeddings(context.Background(), []string{code, "func main() { fmt.Println(\"hi\") }"})
+	require.NoError(t, err)
+	require.Len(t, vectors, 2)
+	assert.Equal(t, [][]string{{code}}, sent, "whitespace is only normalized for the cache key")
+}
+
+func TestEmbeddingCache(t *testing.T) {
+	srv, calls := fakeEmbeddings(t, 8, 0)
+	svc := services.NewEmbeddingService(config.OpenAIConfig{APIKey: "test", BaseURL: srv.URL + "/v1"},
+		models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 8})
+	svc.Cache = services.NewEmbeddingCache(nil, config.EmbeddingCacheConfig{Enabled: true, MemoryEntries: 2})
+	ctx := context.Background()
+
+	_, tokens, err := svc.GetEmbedding(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens)
+
+	// Same text modulo whitespace: served from memory, nothing billed.
+	v, tokens, err := svc.GetEmbedding(ctx, "  doc-1\n")
+	require.NoError(t, err)
+	assert.Equal(t, 0, tokens)
+	assert.Equal(t, float32(1), v[0])
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	// Duplicates in one batch are sent once; doc-1 is evicted (capacity 2).
+	vectors, tokens, err := svc.GetEmbeddings(ctx, []string{"doc-2", "doc-3", "doc-2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokens)
+	assert.Equal(t, float32(2), vectors[2][0])
+
+	_, tokens, err = svc.GetEmbedding(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens)
+
+	stats := svc.Cache.Stats()
+	assert.Equal(t, int64(1), stats.MemoryHits)
+	assert.Equal(t, int64(5), stats.Misses)
+	assert.Equal(t, 2, stats.MemoryEntries)
+
+	var disabled *services.EmbeddingCache
+	assert.Equal(t, services.EmbeddingCacheStats{}, disabled.Stats())
+}
+
+func TestSplitText(t *testing.T) {
+	cfg := config.ChunkingConfig{Size: 12, Overlap: 4}
+	assert.Equal(t, []string{"short"}, services.SplitText("short", cfg))
+	assert.Equal(t, []string{"whole text"}, services.SplitText("whole text", config.ChunkingConfig{}))
+
+	chunks := services.SplitText("alpha beta gamma delta epsilon", cfg)
+	assert.Equal(t, []string{"alpha beta ", "eta gamma ", "mma delta ", "lta epsilon"}, chunks,
+		"pieces end after a space and start Overlap characters back")
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len([]rune(c)), cfg.Size)
+	}
+
+	chunks = services.SplitText(strings.Repeat("x", 25), cfg)
+	assert.Equal(t, []string{strings.Repeat("x", 12), strings.Repeat("x", 12), strings.Repeat("x", 9)}, chunks,
+		"text without spaces is cut at Size")
+}
+
+// axisEmbedder embeds a text as the unit vector along the axis named by its
+// first letter: "a..." is (1, 0), anything else (0, 1).
+type axisEmbedder struct{ calls [][]string }
+
+func (e *axisEmbedder) Embed(_ context.Context, _ models.EmbeddingSpace, texts []string) ([][]float32, int, error) {
+	e.calls = append(e.calls, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.HasPrefix(text, "a") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, len(texts), nil
+}
+
+func TestEmbedDocuments(t *testing.T) {
+	embedder := &axisEmbedder{}
+	space := models.EmbeddingSpace{Model: "m", Dimensions: 2}
+	vectors, tokens, err := services.EmbedDocuments(context.Background(), embedder, space,
+		[]string{"aaaa bbbb", "aaa"}, config.ChunkingConfig{Size: 5, Overlap: 0})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"aaaa ", "bbbb", "aaa"}}, embedder.calls, "every chunk goes in one call")
+	assert.Equal(t, 3, tokens)
+	require.Len(t, vectors, 2)
+	assert.InDelta(t, 1/math.Sqrt2, vectors[0][0], 1e-6, "a split document gets the normalized mean")
+	assert.InDelta(t, 1/math.Sqrt2, vectors[0][1], 1e-6)
+	assert.Equal(t, []float32{1, 0}, vectors[1])
+}
+
+func TestSourcesFingerprint(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := models.SearchResult{Document: models.Document{ID: 1, UpdatedAt: at}}
+	b := models.SearchResult{Document: models.Document{ID: 2, UpdatedAt: at}}
+
+	assert.Equal(t, services.SourcesFingerprint([]models.SearchResult{a, b}), services.SourcesFingerprint([]models.SearchResult{b, a}),
+		"ranking order must not matter")
+
+	edited := b
+	edited.Document.UpdatedAt = at.Add(time.Second)
+	assert.NotEqual(t, services.SourcesFingerprint([]models.SearchResult{a, b}), services.SourcesFingerprint([]models.SearchResult{a, edited}))
+	assert.NotEqual(t, services.SourcesFingerprint([]models.SearchResult{a}), services.SourcesFingerprint([]models.SearchResult{a, b}))
+
+	var disabled *services.AnswerCache
+	_, ok, err := disabled.Lookup(context.Background(), "gpt", models.EmbeddingSpace{}, models.Vector{1}, "")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, services.NewAnswerCache(nil, config.AnswerCacheConfig{Enabled: false}))
 }
 
 func TestSearchService(t *testing.T) {
-	// TODO: Implement search service test
+	// The database-backed pipeline is covered by the integration tests; the
+	// filters are pure.
+	results := []models.SearchResult{
+		{Document: models.Document{ID: 1}, Score: 0.1},
+		{Document: models.Document{ID: 2}, Score: 0.6},
+		{Document: models.Document{ID: 3}, Score: 0.3},
+	}
+	kept := services.MaxDistance(0.5)("query", results)
+	require.Len(t, kept, 2)
+	assert.Equal(t, uint(1), kept[0].Document.ID)
+	assert.Equal(t, uint(3), kept[1].Document.ID)
 }
 
-func TestChatService(t *testing.T) {
-	// TODO: Implement chat service test
+func TestFormatContextForLLM(t *testing.T) {
+	results := []models.SearchResult{
+		{Document: models.Document{Title: "Buttons", Content: "Use the primary variant."}},
+		{Document: models.Document{Title: "Café", Content: "crème brûlée"}},
+	}
+	rendered := services.FormatContextForLLM(results, 10)
+	assert.Equal(t, "Document 1: Buttons\nUse the pr\n\nDocument 2: Café\ncrème br\n\n", rendered,
+		"previews are cut at a character boundary")
+}
+
+func TestSearchServiceWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	mem := store.NewMemory()
+	space := models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 2}
+	for i, v := range []models.Vector{{1, 0}, {0, 1}, {1, 1}} {
+		doc := models.Document{Title: fmt.Sprintf("doc-%d", i)}
+		require.NoError(t, mem.CreateDocument(ctx, &doc, []models.Embedding{
+			{Model: space.Model, Dimensions: space.Dimensions, Vector: v},
+		}))
+	}
+
+	svc := services.NewSearchService(mem, fakeEmbedder{}, space, config.Default().Retrieval)
+	svc.Filters = []services.ResultFilter{services.MaxDistance(0.5)}
+	retrieval, err := svc.Search(ctx, services.SearchQuery{Text: "anything", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, space, retrieval.Space)
+	assert.Equal(t, 1, retrieval.EmbeddingTokens)
+	require.Len(t, retrieval.Results, 2, "doc-1 is orthogonal to the query")
+	assert.Equal(t, "doc-0", retrieval.Results[0].Document.Title)
+	assert.Equal(t, "doc-2", retrieval.Results[1].Document.Title)
+
+	answers := services.NewAnswerCache(mem, config.AnswerCacheConfig{Enabled: true, SimilarityThreshold: 0.95, MaxAgeHours: 1})
+	fingerprint := services.SourcesFingerprint(retrieval.Results)
+	require.NoError(t, mem.LogQuery(ctx, &models.UserQuery{
+		Kind: models.QueryKindChat, Query: "anything", Response: "cached",
+		ChatModel: "fake-chat", EmbeddingModel: space.Model, EmbeddingDimensions: space.Dimensions,
+		QueryEmbedding: retrieval.Vector, SourcesFingerprint: fingerprint,
+	}))
+	hit, ok, err := answers.Lookup(ctx, "fake-chat", space, retrieval.Vector, fingerprint)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "cached", hit.Response)
+	_, ok, _ = answers.Lookup(ctx, "fake-chat", space, models.Vector{0, 1}, fingerprint)
+	assert.False(t, ok, "dissimilar questions miss")
 }
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+func TestMemoryStoreDocuments(t *testing.T) {
+	ctx := context.Background()
+	mem := store.NewMemory()
+	space := models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 2}
+	other := models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 3}
+
+	add := func(title string, vector ...float32) uint {
+		doc := models.Document{Title: title}
+		require.NoError(t, mem.CreateDocument(ctx, &doc, []models.Embedding{
+			{Model: space.Model, Dimensions: space.Dimensions, Vector: vector},
+		}))
+		return doc.ID
+	}
+	east := add("east", 1, 0)
+	add("north", 0, 1)
+	add("north-east", 1, 1)
+	doc := models.Document{Title: "elsewhere"}
+	require.NoError(t, mem.CreateDocument(ctx, &doc, []models.Embedding{
+		{Model: other.Model, Dimensions: other.Dimensions, Vector: models.Vector{1, 0, 0}},
+	}))
+
+	results, err := mem.NearestDocuments(ctx, space, models.Vector{2, 0.1}, 2, store.SearchTuning{})
+	require.NoError(t, err)
+	require.Len(t, results, 2, "only vectors in the searched space count")
+	assert.Equal(t, "east", results[0].Document.Title)
+	assert.Equal(t, "north-east", results[1].Document.Title)
+	assert.InDelta(t, 0.00125, results[0].Score, 1e-4, "cosine distance ignores magnitude")
+
+	docs, total, err := mem.ListDocuments(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "north", docs[0].Title)
+
+	require.NoError(t, mem.DeleteDocument(ctx, east))
+	assert.ErrorIs(t, mem.DeleteDocument(ctx, east), store.ErrNotFound)
+	results, err = mem.NearestDocuments(ctx, space, models.Vector{1, 0}, 10, store.SearchTuning{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2, "deleting a document drops its vectors")
+
+	active, err := mem.ActiveEmbeddingSpace(ctx, space)
+	require.NoError(t, err)
+	assert.Equal(t, space, active)
+	mem.SetActiveEmbeddingSpace(other)
+	active, _ = mem.ActiveEmbeddingSpace(ctx, space)
+	assert.Equal(t, other, active)
+}
+
+func TestMemoryStoreQueryLog(t *testing.T) {
+	ctx := context.Background()
+	mem := store.NewMemory()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	now := day.Add(10 * time.Hour)
+	mem.Now = func() time.Time { return now }
+
+	for _, ev := range []models.UsageEvent{
+		{Endpoint: "chat", UserID: "ada", PromptTokens: 10, CostUSD: 0.5},
+		{Endpoint: "search", UserID: "ada", EmbeddingTokens: 3, CostUSD: 0.1},
+		{Endpoint: "search", EmbeddingTokens: 2},
+		{Endpoint: "search", UserID: "ada", CreatedAt: day.AddDate(0, 0, -1)},
+	} {
+		ev := ev
+		require.NoError(t, mem.RecordUsage(ctx, &ev))
+	}
+	rows, err := mem.UsageReport(ctx, "user", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, models.UsageSummary{Day: day, Principal: "ada", Requests: 2, PromptTokens: 10, EmbeddingTokens: 3, CostUSD: 0.6}, rows[0])
+	assert.Equal(t, "anonymous", rows[1].Principal)
+	_, err = mem.UsageReport(ctx, "team", day, day)
+	assert.Error(t, err)
+
+	space := models.EmbeddingSpace{Model: "fake-embedding", Dimensions: 2}
+	log := func(query string, vector models.Vector, fingerprint string) {
+		require.NoError(t, mem.LogQuery(ctx, &models.UserQuery{
+			Kind: models.QueryKindChat, Query: query, Response: "answer to " + query,
+			ChatModel: "fake-chat", EmbeddingModel: space.Model, EmbeddingDimensions: space.Dimensions,
+			QueryEmbedding: vector, SourcesFingerprint: fingerprint,
+		}))
+	}
+	log("close", models.Vector{1, 0.1}, "fp")
+	log("closer but other sources", models.Vector{1, 0}, "other")
+	log("far", models.Vector{0, 1}, "fp")
+
+	hit, ok, err := mem.NearestAnswer(ctx, store.AnswerMatch{
+		ChatModel: "fake-chat", Space: space, Vector: models.Vector{1, 0}, Fingerprint: "fp", Since: day,
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "close", hit.Query)
+	assert.Greater(t, hit.Similarity, 0.99)
+
+	_, ok, _ = mem.NearestAnswer(ctx, store.AnswerMatch{
+		ChatModel: "fake-chat", Space: space, Vector: models.Vector{1, 0}, Fingerprint: "fp", Since: now,
+	})
+	assert.False(t, ok, "answers older than Since are ignored")
+
+	recent, err := mem.RecentQueries(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, "far", recent[0].Query)
+}
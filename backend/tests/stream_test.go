@@ -0,0 +1,317 @@
+// backend/tests/stream_test.go
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/client"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+const streamBody = `{"messages":[{"role":"user","content":"hello?"}]}`
+
+func newStreamServer(t *testing.T, chat *fakeChat, configure func(*config.Config)) http.Handler {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Environment = "test"
+	if configure != nil {
+		configure(cfg)
+	}
+	h, _ := newTestServerWith(t, cfg, chat)
+	return h
+}
+
+func readEvents(t *testing.T, body string) []client.Event {
+	var events []client.Event
+	require.NoError(t, client.ReadEvents(strings.NewReader(body), func(e client.Event) error {
+		events = append(events, e)
+		return nil
+	}))
+	return events
+}
+
+func eventNames(events []client.Event) []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func TestChatStreamV2Protocol(t *testing.T) {
+	chunks := []string{"Say \"hi\"\n", "<b>é</b> 👋"}
+	h := newStreamServer(t, &fakeChat{chunks: chunks}, nil)
+
+	w := doJSON(h, "POST", "/api/v2/chat/stream", streamBody)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	events := readEvents(t, w.Body.String())
+	require.Equal(t, []string{"start", "sources", "delta", "delta", "usage", "done"}, eventNames(events))
+
+	var start models.StreamStart
+	require.NoError(t, json.Unmarshal(events[0].Data, &start))
+	require.NotEmpty(t, start.ID)
+	for i, e := range events {
+		assert.Equal(t, fmt.Sprintf("%s:%d", start.ID, i+1), e.ID)
+	}
+	for i, chunk := range chunks {
+		var delta models.StreamDelta
+		require.NoError(t, json.Unmarshal(events[2+i].Data, &delta))
+		assert.Equal(t, chunk, delta.Content, "deltas survive JSON framing byte for byte")
+	}
+	var usage models.TokenUsage
+	require.NoError(t, json.Unmarshal(events[4].Data, &usage))
+	assert.Equal(t, 10, usage.PromptTokens)
+	assert.Equal(t, 2, usage.CompletionTokens)
+	assert.JSONEq(t, `{"finish_reason":"stop"}`, string(events[5].Data))
+}
+
+func TestChatStreamV1Framing(t *testing.T) {
+	h := newStreamServer(t, &fakeChat{chunks: []string{"a\nb", "c"}}, nil)
+
+	w := doJSON(h, "POST", "/api/chat/stream", streamBody)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "data: {\"content\":\"a\\nb\"}\n\ndata: {\"content\":\"c\"}\n\ndata: [DONE]\n\n", w.Body.String())
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	h := newStreamServer(t, &fakeChat{err: apierror.New(apierror.CodeProviderRateLimit, "slow down")}, nil)
+	w := doJSON(h, "POST", "/api/v2/chat/stream", streamBody)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"), "nothing was streamed yet")
+
+	h = newStreamServer(t, &fakeChat{chunks: []string{"partial"}, streamErr: apierror.New(apierror.CodeProviderUnavailable, "gone")}, nil)
+	w = doJSON(h, "POST", "/api/v2/chat/stream", streamBody)
+	require.Equal(t, http.StatusOK, w.Code)
+	events := readEvents(t, w.Body.String())
+	require.Equal(t, []string{"start", "sources", "delta", "error"}, eventNames(events))
+	var problem client.Problem
+	require.NoError(t, json.Unmarshal(events[3].Data, &problem))
+	assert.Equal(t, string(apierror.CodeProviderUnavailable), problem.Code)
+	assert.Equal(t, "/api/v2/chat/stream", problem.Instance)
+}
+
+func TestChatStreamResume(t *testing.T) {
+	h := newStreamServer(t, &fakeChat{chunks: []string{"one", "two"}}, nil)
+	events := readEvents(t, doJSON(h, "POST", "/api/v2/chat/stream", streamBody).Body.String())
+	require.Len(t, events, 6)
+	id, _, _ := strings.Cut(events[0].ID, ":")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v2/chat/stream/"+id, nil)
+	req.Header.Set("Last-Event-ID", events[2].ID)
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, events[3:], readEvents(t, w.Body.String()), "replays everything after the last event seen")
+
+	w = doJSON(h, "GET", "/api/v2/chat/stream/"+id+"?last_event_id=other:2", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(h, "GET", "/api/v2/chat/stream/unknown", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// openStream posts a v2 stream request to srv and returns the response.
+func openStream(t *testing.T, ctx context.Context, url string) *http.Response {
+	req, err := http.NewRequestWithContext(ctx, "POST", url+"/api/v2/chat/stream", strings.NewReader(streamBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return resp
+}
+
+func TestChatStreamKeepAlive(t *testing.T) {
+	chat := &fakeChat{chunks: []string{"late"}, hold: make(chan struct{})}
+	srv := httptest.NewServer(newStreamServer(t, chat, func(cfg *config.Config) {
+		cfg.Server.StreamKeepAliveSeconds = 1
+	}))
+	defer srv.Close()
+
+	resp := openStream(t, context.Background(), srv.URL)
+	defer resp.Body.Close()
+	lines := bufio.NewScanner(resp.Body)
+	deadline := time.After(5 * time.Second)
+	found := make(chan bool)
+	go func() {
+		for lines.Scan() {
+			if lines.Text() == ": keep-alive" {
+				found <- true
+				return
+			}
+		}
+		found <- false
+	}()
+	select {
+	case ok := <-found:
+		assert.True(t, ok, "a keep-alive comment is sent while the answer is pending")
+	case <-deadline:
+		t.Fatal("no keep-alive comment")
+	}
+	close(chat.hold)
+}
+
+func TestChatStreamDisconnectCancelsUpstream(t *testing.T) {
+	chat := &fakeChat{chunks: []string{"never"}, hold: make(chan struct{})}
+	srv := httptest.NewServer(newStreamServer(t, chat, func(cfg *config.Config) {
+		cfg.Server.StreamResumeSeconds = 0
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := openStream(t, ctx, srv.URL)
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-chat.upstream().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the provider call was not cancelled when the client left")
+	}
+}
+
+func TestChatStreamSurvivesReconnect(t *testing.T) {
+	chat := &fakeChat{chunks: []string{"first", "second"}, hold: make(chan struct{})}
+	srv := httptest.NewServer(newStreamServer(t, chat, nil))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := openStream(t, ctx, srv.URL)
+	var seen []client.Event
+	chat.hold <- struct{}{}
+	_ = client.ReadEvents(resp.Body, func(e client.Event) error {
+		seen = append(seen, e)
+		if e.Name == "delta" {
+			return context.Canceled // drop the connection part way
+		}
+		return nil
+	})
+	cancel()
+	resp.Body.Close()
+	require.Len(t, seen, 3)
+	assert.NoError(t, chat.upstream().Err(), "generation continues within the resume window")
+
+	id, _, _ := strings.Cut(seen[2].ID, ":")
+	req, err := http.NewRequest("GET", srv.URL+"/api/v2/chat/stream/"+id, nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", seen[2].ID)
+	resumed, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+	chat.hold <- struct{}{}
+
+	var rest []client.Event
+	require.NoError(t, client.ReadEvents(resumed.Body, func(e client.Event) error {
+		rest = append(rest, e)
+		return nil
+	}))
+	assert.Equal(t, []string{"delta", "usage", "done"}, eventNames(rest))
+	assert.JSONEq(t, `{"content":"second"}`, string(rest[0].Data))
+}
+
+func TestChatNegotiatesStream(t *testing.T) {
+	h := newStreamServer(t, &fakeChat{chunks: []string{"a", "b"}}, nil)
+	post := func(path, body, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		h.ServeHTTP(w, req)
+		return w
+	}
+	streamed := `{"messages":[{"role":"user","content":"hello?"}],"stream":true}`
+
+	v1 := post("/api/chat/stream", streamBody, "").Body.String()
+	for _, w := range []*httptest.ResponseRecorder{
+		post("/api/chat", streamed, ""),
+		post("/api/chat", streamBody, "text/event-stream"),
+		post("/api/v1/chat", streamBody, "application/json;q=0.5, text/event-stream"),
+	} {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, v1, w.Body.String(), "/chat streams exactly like /chat/stream")
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		post("/api/v2/chat", streamed, ""),
+		post("/api/v2/chat", streamBody, "text/event-stream"),
+	} {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		events := readEvents(t, w.Body.String())
+		assert.Equal(t, []string{"start", "sources", "delta", "delta", "usage", "done"}, eventNames(events))
+	}
+
+	for _, accept := range []string{"", "application/json", "text/event-stream;q=0.1, application/json", "text/event-stream;q=0"} {
+		w := post("/api/v2/chat", streamBody, accept)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json", "Accept %q", accept)
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	}
+}
+
+func TestChatLogsStreamsLikeAnswers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.RateLimit.Enabled = false
+	space, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	mem := store.NewMemory()
+	chat := &fakeChat{reply: "Use the primary variant."}
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: mem, Queries: mem, Embedder: fakeEmbedder{},
+		Search:  services.NewSearchService(mem, fakeEmbedder{}, space, cfg.Retrieval),
+		Chat:    chat,
+		Answers: services.NewAnswerCache(mem, config.AnswerCacheConfig{Enabled: true, SimilarityThreshold: 0.95, MaxAgeHours: 1}),
+		Clock:   fixedClock{time.Unix(1700000000, 0)},
+	})
+	require.NoError(t, err)
+	h := server.Handler()
+	require.Equal(t, http.StatusCreated, doJSON(h, "POST", "/api/documents", `{"title":"Buttons","content":"Use the primary variant.","url":"https://docs/buttons"}`).Code)
+
+	w := doJSON(h, "POST", "/api/v2/chat", `{"messages":[{"role":"user","content":"Which button?"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	follow := `{"messages":[{"role":"user","content":"Which button?"},{"role":"assistant","content":"Primary."},{"role":"user","content":"Why?"}]}`
+	w = doJSON(h, "POST", "/api/v2/chat/stream", follow)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, chat.last, 4, "streams get the same document context")
+	assert.Contains(t, chat.last[0].Content, "Buttons")
+
+	// The fake embedder puts every question in the same place, so a
+	// standalone streamed question reuses the first answer.
+	chat.last = nil
+	w = doJSON(h, "POST", "/api/v2/chat/stream", `{"messages":[{"role":"user","content":"Which button, again?"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Nil(t, chat.last, "a reused answer does not call the provider")
+	events := readEvents(t, w.Body.String())
+	assert.Equal(t, []string{"start", "sources", "delta", "usage", "done"}, eventNames(events))
+	assert.JSONEq(t, `{"content":"Use the primary variant."}`, string(events[2].Data))
+
+	queries, err := mem.RecentQueries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, queries, 3)
+	for _, q := range queries {
+		assert.Equal(t, models.QueryKindChat, q.Kind, q.Query)
+		assert.Equal(t, []string{"https://docs/buttons"}, []string(q.Sources), q.Query)
+	}
+}
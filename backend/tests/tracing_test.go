@@ -0,0 +1,60 @@
+// backend/tests/tracing_test.go
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestChatIsTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	h, mem, _ := newTestServer(t, "test", "Use a flag.")
+	space, err := config.Default().Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	vector := make(models.Vector, space.Dimensions)
+	vector[0] = 1
+	doc := models.Document{Title: "Flags", Content: "Flags are parsed with the flag package."}
+	require.NoError(t, mem.CreateDocument(context.Background(), &doc, []models.Embedding{
+		{Model: space.Model, Dimensions: space.Dimensions, Vector: vector},
+	}))
+
+	w := doJSON(h, http.MethodPost, "/api/chat", `{"messages":[{"role":"user","content":"How do I parse flags?"}]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	doJSON(h, http.MethodGet, "/livez", "")
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	require.Contains(t, spans, "/api/chat")
+	assert.NotContains(t, spans, "/livez", "probes are not traced")
+	root := spans["/api/chat"].SpanContext().TraceID()
+	for _, name := range []string{"retrieval", "retrieval.embed_query", "retrieval.vector_search", "chat.assemble_context", "llm.chat"} {
+		require.Contains(t, spans, name)
+		assert.Equal(t, root, spans[name].SpanContext().TraceID(), "%s belongs to the request trace", name)
+	}
+	assert.Equal(t, spans["retrieval"].SpanContext().SpanID(), spans["retrieval.vector_search"].Parent().SpanID())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans["llm.chat"].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, int64(10), attrs["gen_ai.usage.input_tokens"].AsInt64())
+	assert.Equal(t, int64(2), attrs["gen_ai.usage.output_tokens"].AsInt64())
+	assert.Equal(t, "fake-chat", attrs["gen_ai.response.model"].AsString())
+}
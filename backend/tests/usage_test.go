@@ -0,0 +1,218 @@
+// backend/tests/usage_test.go
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/internal/api"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+	"github.com/yourname/ai-documentation-assistant/internal/models"
+	"github.com/yourname/ai-documentation-assistant/internal/services"
+	"github.com/yourname/ai-documentation-assistant/internal/store"
+)
+
+func TestLookupModelPrice(t *testing.T) {
+	p, ok := services.LookupModelPrice("gpt-4")
+	require.True(t, ok)
+	assert.Equal(t, services.ModelPrice{PromptPer1K: 0.03, CompletionPer1K: 0.06}, p)
+
+	p, ok = services.LookupModelPrice("gpt-4o-mini-2024-07-18")
+	require.True(t, ok, "dated snapshots use their base model")
+	assert.Equal(t, 0.00015, p.PromptPer1K, "the longest base name wins over gpt-4o and gpt-4")
+
+	for _, model := range []string{"", "gpt-5", "gpt-4omini", "my-gpt-4"} {
+		_, ok = services.LookupModelPrice(model)
+		assert.False(t, ok, model)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	assert.InDelta(t, 0.06, services.EstimateCost("gpt-4", 1000, 500), 1e-12)
+	assert.InDelta(t, 0.0002, services.EstimateCost("text-embedding-ada-002", 2000, 0), 1e-12)
+	assert.InDelta(t, 0.00275, services.EstimateCost("gpt-3.5-turbo-0125", 1000, 1500), 1e-12)
+	assert.Zero(t, services.EstimateCost("unknown-model", 1000, 1000), "unknown models are not guessed")
+}
+
+// newUsageServer serves with a JWT secret and one API key, and records usage
+// at fixed times.
+func newUsageServer(t *testing.T) (http.Handler, *store.Memory) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.Auth.JWTSecret = wsSecret
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{ID: "docs-bot", Key: "bot-key", Collection: "design-system"}}
+	h, mem := newTestServerWith(t, cfg, &fakeChat{reply: "ok"})
+	return h, mem
+}
+
+func bearer(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(wsSecret))
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func doWithHeaders(h http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestUsageReport(t *testing.T) {
+	h, mem := newUsageServer(t)
+	ctx := context.Background()
+	today := time.Unix(1700000000, 0).UTC().Truncate(24 * time.Hour) // the test server's clock
+	yesterday := today.AddDate(0, 0, -1)
+	for _, ev := range []models.UsageEvent{
+		{UserID: "ada", APIKeyID: "docs-bot", PromptTokens: 10, CostUSD: 0.5, CreatedAt: today.Add(time.Hour)},
+		{UserID: "ada", EmbeddingTokens: 4, CostUSD: 0.25, CreatedAt: today.Add(2 * time.Hour)},
+		{UserID: "bob", CompletionTokens: 7, CostUSD: 1, CreatedAt: today.Add(3 * time.Hour)},
+		{CostUSD: 0.1, CreatedAt: today.Add(4 * time.Hour)},
+		{UserID: "ada", PromptTokens: 1, CostUSD: 2, CreatedAt: yesterday.Add(time.Hour)},
+		{UserID: "ada", PromptTokens: 1, CostUSD: 9, CreatedAt: today.AddDate(0, 0, -40)},
+	} {
+		ev := ev
+		require.NoError(t, mem.RecordUsage(ctx, &ev))
+	}
+
+	for _, path := range []string{"/api/v2/analytics/usage", "/api/analytics/usage"} {
+		assert.Equal(t, http.StatusUnauthorized, doJSON(h, "GET", path, "").Code, "%s covers every caller's spend", path)
+		w := doWithHeaders(h, "GET", path, "", map[string]string{"X-API-Key": "bot-key"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "an API key is not enough")
+	}
+
+	report := func(query string) models.UsageReportResponse {
+		w := doWithHeaders(h, "GET", "/api/v2/analytics/usage"+query, "", map[string]string{"Authorization": bearer(t, jwt.MapClaims{"sub": "admin"})})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp models.UsageReportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := report("")
+	assert.Equal(t, "user", resp.GroupBy)
+	assert.Equal(t, today.AddDate(0, 0, -29), resp.From, "the last 30 days by default")
+	assert.Equal(t, today, resp.To)
+	require.Len(t, resp.Rows, 4, "the 40-day-old event is outside the window")
+	assert.Equal(t, models.UsageSummary{Day: today, Principal: "bob", Requests: 1, CompletionTokens: 7, CostUSD: 1}, resp.Rows[0])
+	assert.Equal(t, models.UsageSummary{Day: today, Principal: "ada", Requests: 2, PromptTokens: 10, EmbeddingTokens: 4, CostUSD: 0.75}, resp.Rows[1])
+	assert.Equal(t, models.UsageSummary{Day: today, Principal: "anonymous", Requests: 1, CostUSD: 0.1}, resp.Rows[2])
+	assert.Equal(t, models.UsageSummary{Day: yesterday, Principal: "ada", Requests: 1, PromptTokens: 1, CostUSD: 2}, resp.Rows[3])
+
+	resp = report("?group_by=api_key&from=" + yesterday.Format("2006-01-02") + "&to=" + yesterday.Format("2006-01-02"))
+	require.Len(t, resp.Rows, 1, "to includes the whole day, and nothing after it")
+	assert.Equal(t, "anonymous", resp.Rows[0].Principal)
+	resp = report("?group_by=api_key&from=" + today.Format("2006-01-02"))
+	require.Len(t, resp.Rows, 2)
+	assert.Equal(t, "anonymous", resp.Rows[0].Principal)
+	assert.Equal(t, int64(3), resp.Rows[0].Requests)
+	assert.Equal(t, "docs-bot", resp.Rows[1].Principal)
+
+	token := map[string]string{"Authorization": bearer(t, jwt.MapClaims{"sub": "admin"})}
+	for _, query := range []string{"?group_by=team", "?from=yesterday", "?to=2023-13-01", "?from=2023-11-14&to=2023-11-01"} {
+		assert.Equal(t, http.StatusBadRequest, doWithHeaders(h, "GET", "/api/v2/analytics/usage"+query, "", token).Code, query)
+	}
+}
+
+func TestUsageAttribution(t *testing.T) {
+	h, mem := newUsageServer(t)
+	search := func(headers map[string]string) int {
+		return doWithHeaders(h, "POST", "/api/v2/search", `{"query":"buttons"}`, headers).Code
+	}
+
+	assert.Equal(t, http.StatusOK, search(nil), "public routes need no credentials")
+	assert.Equal(t, http.StatusOK, search(map[string]string{"X-API-Key": "bot-key", "X-Collection": "someone-else"}))
+	assert.Equal(t, http.StatusOK, search(map[string]string{"Authorization": bearer(t, jwt.MapClaims{"sub": "ada", "collection": "guides"})}))
+	assert.Equal(t, http.StatusUnauthorized, search(map[string]string{"X-API-Key": "guessed"}), "unknown keys are refused, not billed")
+	assert.Equal(t, http.StatusUnauthorized, search(map[string]string{"Authorization": "Bearer forged"}))
+
+	principals := func(groupBy string) []string {
+		rows, err := mem.UsageReport(context.Background(), groupBy, time.Time{}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.Principal)
+		}
+		return out
+	}
+	assert.ElementsMatch(t, []string{"anonymous", "ada"}, principals("user"))
+	assert.ElementsMatch(t, []string{"anonymous", "docs-bot"}, principals("api_key"))
+	assert.ElementsMatch(t, []string{"anonymous", "design-system", "guides"}, principals("collection"),
+		"collections come from the key or token, never the X-Collection header")
+}
+
+// endpointLog is a Memory that also remembers the endpoint of every usage
+// event, in order.
+type endpointLog struct {
+	*store.Memory
+	mu        sync.Mutex
+	endpoints []string
+}
+
+func (l *endpointLog) RecordUsage(ctx context.Context, ev *models.UsageEvent) error {
+	l.mu.Lock()
+	l.endpoints = append(l.endpoints, ev.Endpoint)
+	l.mu.Unlock()
+	return l.Memory.RecordUsage(ctx, ev)
+}
+
+func (l *endpointLog) recorded() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.endpoints...)
+}
+
+func TestAnswerUsageEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Environment = "test"
+	cfg.RateLimit.Enabled = false
+	cfg.Auth.JWTSecret = wsSecret
+	space, err := cfg.Providers.OpenAI.EmbeddingSpace()
+	require.NoError(t, err)
+	log := &endpointLog{Memory: store.NewMemory()}
+	server, err := api.NewServer(api.Deps{
+		Config: cfg, Documents: log.Memory, Queries: log, Embedder: fakeEmbedder{},
+		Search: services.NewSearchService(log.Memory, fakeEmbedder{}, space, cfg.Retrieval),
+		Chat:   &fakeChat{reply: "ok", chunks: []string{"o", "k"}},
+		Clock:  fixedClock{time.Unix(1700000000, 0)},
+	})
+	require.NoError(t, err)
+	h := server.Handler()
+	token := map[string]string{"Authorization": bearer(t, jwt.MapClaims{"sub": "ada"})}
+	question := `{"messages":[{"role":"user","content":"Which button?"}]}`
+
+	require.Equal(t, http.StatusOK, doJSON(h, "POST", "/api/chat", question).Code)
+	require.Equal(t, http.StatusOK, doJSON(h, "POST", "/api/chat/stream", question).Code)
+	w := doWithHeaders(h, "POST", "/api/v2/chat", question, map[string]string{"Accept": "text/event-stream"})
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"), "negotiated streams count as streams")
+	require.Equal(t, http.StatusOK, doWithHeaders(h, "POST", "/v1/chat/completions", `{"messages":[{"role":"user","content":"Which button?"}]}`, token).Code)
+	require.Equal(t, http.StatusOK, doWithHeaders(h, "POST", "/v1/chat/completions", `{"stream":true,"messages":[{"role":"user","content":"Which button?"}]}`, token).Code)
+	require.Equal(t, []string{"chat", "chat_stream", "chat_stream", "v1_chat_completions", "v1_chat_completions"}, log.recorded())
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	conn := dialWS(t, "ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws")
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"type": "chat", "id": "q1",
+		"messages": []map[string]string{{"role": "user", "content": "Which button?"}},
+	}))
+	readUntil(t, conn, "done")
+	assert.Eventually(t, func() bool { return len(log.recorded()) == 6 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "ws", log.recorded()[5])
+}
@@ -0,0 +1,179 @@
+// backend/tests/ws_test.go
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourname/ai-documentation-assistant/client"
+	"github.com/yourname/ai-documentation-assistant/internal/apierror"
+	"github.com/yourname/ai-documentation-assistant/internal/config"
+)
+
+const wsSecret = "0123456789abcdef0123456789abcdef"
+
+type wsEvent struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// newWSServer serves chat with a JWT secret set and returns its ws:// URL.
+func newWSServer(t *testing.T, chat *fakeChat, configure func(*config.Config)) string {
+	srv := httptest.NewServer(newStreamServer(t, chat, func(cfg *config.Config) {
+		cfg.Auth.JWTSecret = wsSecret
+		if configure != nil {
+			configure(cfg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws"
+}
+
+func wsToken(t *testing.T) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"}).SignedString([]byte(wsSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func dialWS(t *testing.T, url string) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial(url+"?access_token="+wsToken(t), nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readUntil reads events until one of type last (or an error) arrives.
+func readUntil(t *testing.T, conn *websocket.Conn, last string) []wsEvent {
+	var events []wsEvent
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		var e wsEvent
+		require.NoError(t, conn.ReadJSON(&e))
+		events = append(events, e)
+		if e.Type == last || e.Type == "error" {
+			return events
+		}
+	}
+}
+
+func wsProblem(t *testing.T, e wsEvent) client.Problem {
+	require.Equal(t, "error", e.Type)
+	var p client.Problem
+	require.NoError(t, json.Unmarshal(e.Data, &p))
+	return p
+}
+
+func TestWebSocketRequiresToken(t *testing.T) {
+	url := newWSServer(t, &fakeChat{}, nil)
+	for _, suffix := range []string{"", "?access_token=not-a-jwt"} {
+		_, resp, err := websocket.DefaultDialer.Dial(url+suffix, nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	header := http.Header{"Authorization": {"Bearer " + wsToken(t)}}
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err, "the Authorization header works as well")
+	resp.Body.Close()
+	conn.Close()
+}
+
+func TestWebSocketChat(t *testing.T) {
+	conn := dialWS(t, newWSServer(t, &fakeChat{chunks: []string{"Use ", "primary."}}, nil))
+
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"type": "chat", "id": "q1",
+		"messages": []map[string]string{{"role": "user", "content": "Which button?"}},
+	}))
+	events := readUntil(t, conn, "done")
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+		assert.Equal(t, "q1", e.ID)
+	}
+	assert.Equal(t, []string{"progress", "progress", "start", "sources", "delta", "delta", "usage", "done"}, types)
+	assert.JSONEq(t, `{"stage":"retrieving"}`, string(events[0].Data))
+	assert.JSONEq(t, `{"stage":"generating"}`, string(events[1].Data))
+	assert.JSONEq(t, `{"content":"primary."}`, string(events[5].Data))
+
+	// Bad messages are answered with an error; the connection stays open.
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	assert.Equal(t, string(apierror.CodeInvalidRequest), wsProblem(t, readUntil(t, conn, "error")[0]).Code)
+	require.NoError(t, conn.WriteJSON(map[string]any{"type": "chat", "id": "q2", "messages": []any{}}))
+	assert.Equal(t, string(apierror.CodeInvalidRequest), wsProblem(t, readUntil(t, conn, "error")[0]).Code)
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"type": "chat", "id": "q3",
+		"messages": []map[string]string{{"role": "user", "content": "Again?"}},
+	}))
+	assert.Equal(t, "done", readUntil(t, conn, "done")[7].Type)
+}
+
+func TestWebSocketCancel(t *testing.T) {
+	chat := &fakeChat{chunks: []string{"never"}, hold: make(chan struct{})}
+	conn := dialWS(t, newWSServer(t, chat, nil))
+
+	ask := map[string]any{
+		"type": "chat", "id": "long",
+		"messages": []map[string]string{{"role": "user", "content": "Tell me everything"}},
+	}
+	require.NoError(t, conn.WriteJSON(ask))
+	readUntil(t, conn, "sources")
+
+	ask["id"] = "second"
+	require.NoError(t, conn.WriteJSON(ask))
+	e := readUntil(t, conn, "error")[0]
+	assert.Equal(t, "second", e.ID)
+	assert.Equal(t, string(apierror.CodeRateLimited), wsProblem(t, e).Code, "one answer at a time by default")
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "cancel", "id": "long"}))
+	e = readUntil(t, conn, "error")[0]
+	assert.Equal(t, "long", e.ID)
+	assert.Equal(t, string(apierror.CodeRequestCancelled), wsProblem(t, e).Code)
+	assert.Error(t, chat.upstream().Err(), "cancelling stops the provider call")
+}
+
+func TestWebSocketLimits(t *testing.T) {
+	url := newWSServer(t, &fakeChat{}, func(cfg *config.Config) {
+		cfg.WebSocket.MaxMessageBytes = 64
+		cfg.WebSocket.PingSeconds = 1
+	})
+
+	conn := dialWS(t, url)
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case <-pinged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not ping")
+	}
+
+	conn = dialWS(t, url)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 100))))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "got %v", err)
+}
@@ -46,7 +46,7 @@ echo ""
 echo "Setup complete!"
 echo ""
 echo "Next:"
-echo "  1) cd backend && go run cmd/migrate/main.go"
+echo "  1) cd backend && go run ./cmd/migrate up"
 echo "  2) cd backend && go run cmd/server/main.go"
 echo "  3) cd frontend && npm install && npm run dev"
 echo ""
@@ -7,7 +7,7 @@ echo "1) Starting Postgres (pgvector) ..."
 docker-compose -f docker/docker-compose.yml up -d
 
 echo "2) Applying migrations ..."
-(cd backend && go run cmd/migrate/main.go)
+(cd backend && go run ./cmd/migrate up)
 
 echo "3) Starting backend ..."
 (cd backend && go run cmd/server/main.go) &